@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/urfave/cli/v2"
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "config"
+	app.Usage = "Inspect the layered application config."
+
+	app.Commands = []*cli.Command{
+		{
+			Name:    "print",
+			Aliases: []string{"effective"},
+			Usage:   "Print the effective config with the file or environment variable setting each key, secrets are redacted",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "config-dir",
+					Value: config.DefaultDir,
+					Usage: "directory of config.yaml, config-<env>.yaml and config.local.yaml",
+				},
+				&cli.StringFlag{
+					Name:    "env",
+					EnvVars: []string{"APP_ENV"},
+					Value:   "local",
+					Usage:   "environment layer to merge, config-<env>.yaml",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				values, err := config.Effective(c.String("config-dir"), c.String("env"))
+				if err != nil {
+					return err
+				}
+
+				writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(writer, "KEY\tVALUE\tSOURCE")
+				for _, value := range values {
+					fmt.Fprintf(writer, "%s\t%v\t%s\n", value.Key, value.Value, value.Source)
+				}
+				return writer.Flush()
+			},
+		},
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(2)
+	}
+}
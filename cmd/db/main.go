@@ -2,16 +2,13 @@ package main
 
 import (
 	"fmt"
-	"net/url"
 	"os"
 	"regexp"
 
 	"github.com/amacneil/dbmate/v2/pkg/dbmate"
 	"github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/mochammadshenna/arch-pba-template/database"
 	"github.com/mochammadshenna/arch-pba-template/internal/state"
-	"github.com/mochammadshenna/arch-pba-template/internal/util/helper"
-
-	_ "github.com/amacneil/dbmate/v2/pkg/driver/mysql"
 	"github.com/urfave/cli/v2"
 )
 
@@ -109,26 +106,10 @@ func main() {
 
 func action(f func(*dbmate.DB, *cli.Context) error) cli.ActionFunc {
 	config.Init(state.App.Environment)
-	dbConfig := config.Get().Database
 	return func(c *cli.Context) error {
-		link := fmt.Sprintf("%s://%s:%s@%s:%s/%s",
-			"mysql",
-			dbConfig.Username,
-			dbConfig.Password,
-			dbConfig.Host,
-			dbConfig.Port,
-			dbConfig.DbName,
-		)
-
-		// link := "mysql://shenna:Aqilah@21@localhost:3306/arch_db"
-
-		u, err := url.Parse(link)
-		helper.PanicError(err)
-
-		db := dbmate.New(u)
+		db := database.NewMigrator()
 		db.AutoDumpSchema = !c.Bool("no-dump-schema")
 		db.SchemaFile = c.String("schema-file")
-		db.MigrationsDir = []string{"./scripts/migrations"}
 
 		return f(db, c)
 	}
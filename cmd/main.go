@@ -1,30 +1,30 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"net/http"
+	"log"
 
 	_ "github.com/go-sql-driver/mysql"
 	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/mochammadshenna/arch-pba-template/internal/app"
 	"github.com/mochammadshenna/arch-pba-template/internal/state"
-	"github.com/mochammadshenna/arch-pba-template/internal/util/helper"
 	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
 )
 
 func main() {
-	config.Init(state.App.Environment)
-	logger.Init()
+	configDir := flag.String("config-dir", config.DefaultDir, "directory of config.yaml, config-<env>.yaml and config.local.yaml")
+	flag.Parse()
 
-	host := fmt.Sprintf("%s:%d", config.Get().Server.Host, config.Get().Server.Port)
-	fmt.Printf("Server running on host:%d \n", config.Get().Server.Port)
+	container, err := app.Build(app.Options{Environment: state.App.Environment, ConfigDir: *configDir})
+	if err != nil {
+		log.Fatalf("failed to start: %v", err)
+	}
 
-	// router := routes.NewRouter(PbaController)
+	fmt.Printf("Server running on host:%d \n", config.Get().Server.Port)
 
-	server := http.Server{
-		Addr: host,
-		// Handler: router,
+	if err := container.Lifecycle.Run(config.Get().Server.ShutdownGracePeriod, container.Servers()...); err != nil {
+		logger.Fatal(context.Background(), err)
 	}
-
-	err := server.ListenAndServe()
-	helper.PanicError(err)
 }
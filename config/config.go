@@ -2,66 +2,293 @@ package config
 
 import (
 	"log"
+	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
 type (
 	Config struct {
-		Server   ServerConfig
-		Database DatabaseConfig
-		Log      LogConfig
+		Server      ServerConfig
+		Database    DatabaseConfig
+		Redis       RedisConfig
+		Log         LogConfig
+		Alert       AlertConfig
+		Auth        AuthConfig
+		Client      ClientConfig
+		Cors        CorsConfig
+		RateLimit   RateLimitConfig
+		Idempotency IdempotencyConfig
+		Maintenance MaintenanceConfig
+		Health      HealthConfig
+		Admin       AdminConfig
 	}
 
 	ServerConfig struct {
-		Host string
-		Port int
+		Host                string        `validate:"required"`
+		Port                int           `validate:"min=1,max=65535"`
+		RequestTimeout      time.Duration `validate:"min=0s"` // default deadline of a request, 0 disables it and needs writeTimeout 0
+		ShutdownGracePeriod time.Duration `validate:"min=0s"` // time to drain requests and stop components on SIGTERM
+		DrainDelay          time.Duration `validate:"min=0s"` // readiness fails this long before the listeners close on SIGTERM
+		ReadHeaderTimeout   time.Duration `validate:"min=0s"`
+		ReadTimeout         time.Duration `validate:"min=0s"`
+		WriteTimeout        time.Duration `validate:"min=0s"` // keep above requestTimeout so the timeout response can be written
+		IdleTimeout         time.Duration `validate:"min=0s"`
+		MaxHeaderBytes      int           `validate:"min=0"`
+		Tls                 TlsConfig
+		H2c                 bool     // cleartext HTTP/2 behind the internal load balancer, ignored with TLS
+		TrustedProxies      []string `validate:"dive,ip|cidr"` // IPs or CIDRs of the load balancer and ingress, X-Forwarded-For is only read from them
+	}
+
+	TlsConfig struct {
+		CertFile   string `validate:"required_with=KeyFile"` // TLS is served when both files are set, they are reloaded when they change
+		KeyFile    string `validate:"required_with=CertFile"`
+		MinVersion string `validate:"omitempty,oneof=1.2 1.3"` // 1.2 | 1.3
 	}
 
 	DatabaseConfig struct {
+		Host     string `validate:"required"`
+		Port     string `validate:"required,numeric"`
+		DbName   string `validate:"required"`
+		Username string `validate:"required"`
+		Password string `secret:"true"`
+
+		MaxOpenConns    int           `validate:"min=0"`  // 0 is unlimited
+		MaxIdleConns    int           `validate:"min=0"`  // capped to maxOpenConns by database/sql
+		ConnMaxLifetime time.Duration `validate:"min=0s"` // keep below the server wait_timeout and the proxy idle timeout
+		ConnMaxIdleTime time.Duration `validate:"min=0s"`
+
+		ParseTime    bool          // scan DATE and DATETIME into time.Time
+		Loc          string        `validate:"omitempty,timezone"` // location of time.Time values, UTC when empty
+		Charset      string        // e.g. utf8mb4, the server default when empty
+		DialTimeout  time.Duration `validate:"min=0s"`
+		ReadTimeout  time.Duration `validate:"min=0s"`
+		WriteTimeout time.Duration `validate:"min=0s"`
+		Tls          string        `validate:"omitempty,oneof=false true skip-verify preferred"` // false | true | skip-verify | preferred
+		TlsCaFile    string        // PEM CA verifying the server, e.g. the Cloud SQL server CA, implies tls true
+
+		Stats DatabaseStatsConfig
+	}
+
+	DatabaseStatsConfig struct {
+		Interval      time.Duration `validate:"min=0s"` // how often the pool stats are logged, 0 disables it
+		WaitCountWarn int64         `validate:"min=0"`  // warn when more connections than this were waited for within an interval, 0 disables it
+	}
+
+	RedisConfig struct {
 		Host     string
-		Port     string
-		DbName   string
-		Username string
-		Password string
+		Port     string `validate:"omitempty,numeric"`
+		Password string `secret:"true"`
+		Db       int    `validate:"min=0,max=15"`
 	}
 
 	LogConfig struct {
-		Level string
+		Level  string `validate:"required,oneof=trace debug info warn warning error fatal panic"`
+		Access AccessLogConfig
+	}
+
+	AccessLogConfig struct {
+		SkipPaths []string
+	}
+
+	AlertConfig struct {
+		WebhookUrl string `secret:"true" validate:"omitempty,http_url"` // google chat webhook, alerts are disabled when empty
+	}
+
+	AuthConfig struct {
+		Algorithm       string                  `validate:"oneof=HS256 RS256"`                         // HS256 | RS256
+		Secret          string                  `secret:"true" validate:"required_if=Algorithm HS256"` // HS256 signing secret
+		PrivateKeyFile  string                  `validate:"required_if=Algorithm RS256"`               // RS256 PEM private key
+		PublicKeyFile   string                  // RS256 PEM public key, derived from the private key when empty
+		Issuer          string                  `validate:"required"`
+		AccessTokenTtl  time.Duration           `validate:"min=1s"`
+		RefreshTokenTtl time.Duration           `validate:"gtfield=AccessTokenTtl"`
+		Store           string                  `validate:"oneof=memory redis"` // memory | redis, keeps refresh tokens and revoked access tokens
+		ApiKeys         map[string]ApiKeyConfig `validate:"dive"`               // partner integrations by name, sent in the X-Api-Key header
+	}
+
+	ApiKeyConfig struct {
+		Key string `secret:"true" validate:"required,min=16"`
+	}
+
+	ClientConfig struct {
+		MinVersions map[string]string `validate:"dive,keys,oneof=android ios web,endkeys,semver"`
+	}
+
+	CorsConfig struct {
+		AllowedOrigins   []string `validate:"dive,required"` // exact origin, a wildcard subdomain like https://*.example.com, or "*" without allowCredentials
+		AllowedMethods   []string
+		AllowedHeaders   []string
+		ExposedHeaders   []string
+		AllowCredentials bool
+		MaxAge           time.Duration `validate:"min=0s"`
+	}
+
+	RateLimitConfig struct {
+		Store  string                   `validate:"oneof=memory redis"` // memory | redis
+		Groups map[string]RateLimitRule `validate:"dive"`
+	}
+
+	RateLimitRule struct {
+		Limit  int           `validate:"min=0"`
+		Window time.Duration `validate:"min=0s"`
+		Key    string        `validate:"omitempty,oneof=ip apiKey user"` // ip | apiKey | user
+	}
+
+	IdempotencyConfig struct {
+		Store string        `validate:"oneof=memory redis"` // memory | redis
+		Ttl   time.Duration `validate:"min=1s"`
+	}
+
+	AdminConfig struct {
+		Enabled bool
+		Host    string `validate:"required_if=Enabled true"` // a loopback host is reachable from inside the pod only, e.g. kubectl port-forward
+		Port    int    `validate:"required_if=Enabled true,min=0,max=65535"`
+		Token   string `secret:"true"` // bearer token, required unless host is loopback
+	}
+
+	HealthConfig struct {
+		CheckTimeout time.Duration `validate:"min=0s"` // per readiness check
+		CacheTtl     time.Duration `validate:"min=0s"` // how long a check result is reused by the next probes
+	}
+
+	MaintenanceConfig struct {
+		Mode         string `validate:"omitempty,oneof=off readOnly full"` // off | readOnly | full, readOnly only blocks mutating methods
+		Message      string
+		RetryAfter   time.Duration `validate:"min=0s"`
+		AllowedIps   []string      `validate:"dive,ip|cidr"` // client IPs or CIDRs that bypass maintenance, e.g. the office VPN
+		ExemptRoutes []string      // route patterns that stay available, a trailing * matches a prefix
 	}
 )
 
-var config Config
+var (
+	current       atomic.Pointer[Config]
+	subscribersMu sync.Mutex
+	subscribers   []func(old, new Config)
+)
 
+// Init loads the config files of DefaultDir, see InitFrom.
 func Init(env string) {
+	InitFrom(DefaultDir, env)
+}
 
+// InitFrom loads config.yaml, config-<env>.yaml and config.local.yaml of dir, then the APP_
+// environment variables, and reloads them when one of the files changes.
+func InitFrom(dir, env string) {
 	if len(env) == 0 {
 		env = "local"
 	}
 
-	viper.AddConfigPath("config/")
-	viper.SetConfigName("config-" + env)
-	viper.SetConfigType("yaml")
-
 	// get application config
-	err := viper.ReadInConfig()
+	c, _, err := load(dir, env)
 	panicOnError(err)
+	swap(c)
 
-	err = viper.Unmarshal(&config)
+	err = watch(dir, env)
 	panicOnError(err)
+}
 
-	err = viper.Unmarshal(&config)
-	panicOnError(err)
+// Get returns the current snapshot without copying it, call it again to see a reloaded config.
+// A snapshot is shared by every caller and never changes, it must not be modified.
+func Get() *Config {
+	if c := current.Load(); c != nil {
+		return c
+	}
+	return &Config{}
+}
+
+// Set replaces the current config and notifies the subscribers, tests use it instead of Init.
+func Set(c Config) {
+	swap(c)
+}
 
-	viper.OnConfigChange(func(e fsnotify.Event) {
-		viper.Unmarshal(&config)
-	})
-	viper.WatchConfig()
+// Subscribe calls fn with the previous and the new config after Init, Set and every accepted reload.
+// Subscribers run one at a time on the watcher goroutine, should return quickly and must not
+// call Subscribe or Set.
+func Subscribe(fn func(old, new Config)) {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	subscribers = append(subscribers, fn)
+}
+
+// load merges the layers of dir and the environment into a config, it is only returned when it is
+// valid. sources tells which file or environment variable set each key.
+func load(dir, env string) (Config, map[string]string, error) {
+	c, m, err := loadMerged(dir, env)
+	return c, m.sources, err
+}
+
+// merged is the viper behind a loaded config, Effective lists it so it accepts exactly the
+// configs the server starts with.
+type merged struct {
+	v *viper.Viper
+	// keys are listed from the files, after an env override the viper only returns the
+	// overridden entries of a map
+	keys    []envKey
+	sources map[string]string
+}
+
+// loadMerged is load that also returns the merged viper.
+func loadMerged(dir, env string) (Config, merged, error) {
+	var c Config
+
+	v, sources, err := readLayers(dir, env)
+	if err != nil {
+		return c, merged{}, err
+	}
+	m := merged{v: v, keys: configKeys(v), sources: sources}
+
+	envSources, err := applyEnv(v, m.keys)
+	if err != nil {
+		return c, merged{}, err
+	}
+	for key, source := range envSources {
+		sources[key] = source
+	}
+
+	if err := checkUnknownKeys(v, m.keys); err != nil {
+		return c, merged{}, err
+	}
+	if err := v.Unmarshal(&c); err != nil {
+		return c, merged{}, err
+	}
+	return c, m, Validate(c)
+}
+
+// reload keeps the current config when the changed files are invalid, the process keeps running
+// with the last valid config until they are fixed.
+func reload(dir, env string) {
+	c, _, err := load(dir, env)
+	if err != nil {
+		log.Printf("rejected config reload, keeping the current config: %v", err)
+		return
+	}
+	swap(c)
+	log.Printf("config reloaded")
+}
+
+func swap(c Config) {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+
+	old := current.Swap(&c)
+	if old == nil {
+		old = &Config{}
+	}
+	for _, fn := range subscribers {
+		notify(fn, *old, c)
+	}
 }
 
-func Get() Config {
-	return config
+func notify(fn func(old, new Config), old, new Config) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("config subscriber panicked: %v", r)
+		}
+	}()
+	fn(old, new)
 }
 
 func panicOnError(err error) {
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// layerDir copies the tracked config files into a temp dir, tests write config.local.yaml there.
+func layerDir(t *testing.T) string {
+	dir := t.TempDir()
+	for _, file := range []string{baseFile, "config-local.yaml"} {
+		content, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, file), content, 0o600))
+	}
+	return dir
+}
+
+func TestLoadMergesLayers(t *testing.T) {
+	dir := layerDir(t)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, localFile), []byte("server:\n  port: 6000\n"), 0o600))
+	t.Setenv("APP_DATABASE_DB_NAME", "env_db")
+
+	c, sources, err := load(dir, "local")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", c.Server.Host)
+	assert.Equal(t, 6000, c.Server.Port)
+	assert.Equal(t, "debug", c.Log.Level)
+	assert.Equal(t, "env_db", c.Database.DbName)
+	assert.Equal(t, filepath.Join(dir, baseFile), sources["server.requesttimeout"])
+	assert.Equal(t, filepath.Join(dir, "config-local.yaml"), sources["server.host"])
+	assert.Equal(t, filepath.Join(dir, localFile), sources["server.port"])
+	assert.Equal(t, "APP_DATABASE_DB_NAME", sources["database.dbname"])
+}
+
+func TestLoadRequiresBaseFile(t *testing.T) {
+	_, _, err := load(t.TempDir(), "local")
+
+	assert.ErrorContains(t, err, baseFile)
+}
+
+func TestReload(t *testing.T) {
+	dir := layerDir(t)
+	local := filepath.Join(dir, localFile)
+
+	c, _, err := load(dir, "local")
+	assert.NoError(t, err)
+	Set(c)
+
+	levels := [][2]string{}
+	Subscribe(func(old, new Config) {
+		levels = append(levels, [2]string{old.Log.Level, new.Log.Level})
+	})
+
+	// an invalid file keeps the current config
+	assert.NoError(t, os.WriteFile(local, []byte("log:\n  level: \"verbose\"\n"), 0o600))
+	reload(dir, "local")
+	assert.Equal(t, "debug", Get().Log.Level)
+	assert.Empty(t, levels)
+
+	assert.NoError(t, os.WriteFile(local, []byte("log:\n  level: \"warn\"\n"), 0o600))
+	reload(dir, "local")
+	assert.Equal(t, "warn", Get().Log.Level)
+	assert.Equal(t, [][2]string{{"debug", "warn"}}, levels)
+}
+
+func TestEffective(t *testing.T) {
+	dir := layerDir(t)
+	t.Setenv("APP_DATABASE_PASSWORD", "hunter2")
+
+	values, err := Effective(dir, "local")
+
+	assert.NoError(t, err)
+	byKey := map[string]Value{}
+	for _, value := range values {
+		byKey[value.Key] = value
+	}
+	assert.Equal(t, Value{Key: "database.password", Value: redacted, Source: "APP_DATABASE_PASSWORD"}, byKey["database.password"])
+	assert.Equal(t, Value{Key: "server.host", Value: "127.0.0.1", Source: filepath.Join(dir, "config-local.yaml")}, byKey["server.host"])
+}
+
+func TestEffectiveRejectsUnknownKeys(t *testing.T) {
+	dir := layerDir(t)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, localFile), []byte("server:\n  prot: 6000\n"), 0o600))
+
+	_, err := Effective(dir, "local")
+
+	assert.EqualError(t, err, "unknown config keys: server.prot")
+}
+
+func TestLoadOverridesMapEntryFromEnv(t *testing.T) {
+	dir := layerDir(t)
+	t.Setenv("APP_RATE_LIMIT_GROUPS_PUBLIC_LIMIT", "5")
+	t.Setenv("APP_CLIENT_MIN_VERSIONS_ANDROID", "2.0.0")
+
+	c, sources, err := load(dir, "local")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, c.RateLimit.Groups["public"].Limit)
+	assert.Equal(t, "APP_RATE_LIMIT_GROUPS_PUBLIC_LIMIT", sources["ratelimit.groups.public.limit"])
+	assert.Equal(t, 600, c.RateLimit.Groups["api"].Limit)
+	assert.NotZero(t, c.RateLimit.Groups["cms"].Window)
+	assert.Equal(t, map[string]string{"android": "2.0.0", "ios": "1.0.0"}, c.Client.MinVersions)
+
+	values, err := Effective(dir, "local")
+	assert.NoError(t, err)
+	keys := []string{}
+	for _, value := range values {
+		keys = append(keys, value.Key)
+	}
+	assert.Contains(t, keys, "rateLimit.groups.cms.window")
+	assert.Contains(t, keys, "client.minVersions.ios")
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	envPrefix     = "APP"
+	envFileSuffix = "_FILE"
+)
+
+// envKey is a config key and the environment variable overriding it,
+// e.g. database.dbName and APP_DATABASE_DB_NAME.
+type envKey struct {
+	key    string
+	env    string
+	secret bool
+}
+
+// applyEnv overrides v with APP_<KEY> environment variables, or with the content of the file named
+// by APP_<KEY>_FILE for mounted secrets, and returns the variable used for each key. Lists are comma
+// separated. Map entries, like rateLimit.groups.public.limit, can be overridden when the entry
+// exists in a file. keys are the configKeys of v.
+func applyEnv(v *viper.Viper, keys []envKey) (map[string]string, error) {
+	sources := map[string]string{}
+	var errs []error
+	for _, k := range keys {
+		value, hasValue := os.LookupEnv(k.env)
+		file, hasFile := os.LookupEnv(k.env + envFileSuffix)
+
+		switch {
+		case hasValue && hasFile:
+			errs = append(errs, fmt.Errorf("both %s and %s%s are set", k.env, k.env, envFileSuffix))
+		case hasValue:
+			v.Set(k.key, value)
+			sources[strings.ToLower(k.key)] = k.env
+		case hasFile:
+			content, err := os.ReadFile(file)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("read %s%s: %w", k.env, envFileSuffix, err))
+				continue
+			}
+			v.Set(k.key, strings.TrimRight(string(content), "\r\n"))
+			sources[strings.ToLower(k.key)] = k.env + envFileSuffix
+		}
+	}
+	return sources, errors.Join(errs...)
+}
+
+// configKeys lists every key of Config with the map entries present in v. List them before
+// applyEnv, an override of a map entry hides the other entries of the map from v.GetStringMap.
+func configKeys(v *viper.Viper) []envKey {
+	return envKeys(v, reflect.TypeOf(Config{}), "", envPrefix)
+}
+
+// envKeys lists every config key of t, map entries are listed from the ones present in v.
+func envKeys(v *viper.Viper, t reflect.Type, key, env string) []envKey {
+	keys := []envKey{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldKey := joinKey(key, lowerFirst(field.Name))
+		fieldEnv := env + "_" + snakeCase(field.Name)
+
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			keys = append(keys, envKeys(v, field.Type, fieldKey, fieldEnv)...)
+		case field.Type.Kind() == reflect.Map:
+			for entry := range v.GetStringMap(fieldKey) {
+				entryKey := fieldKey + "." + entry
+				entryEnv := fieldEnv + "_" + strings.ToUpper(entry)
+				if field.Type.Elem().Kind() == reflect.Struct {
+					keys = append(keys, envKeys(v, field.Type.Elem(), entryKey, entryEnv)...)
+				} else {
+					keys = append(keys, envKey{key: entryKey, env: entryEnv})
+				}
+			}
+		default:
+			keys = append(keys, envKey{key: fieldKey, env: fieldEnv, secret: field.Tag.Get("secret") == "true"})
+		}
+	}
+	return keys
+}
+
+func joinKey(key, name string) string {
+	if key == "" {
+		return name
+	}
+	return key + "." + name
+}
+
+func lowerFirst(s string) string {
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// snakeCase turns a field name into its environment variable part, e.g. DbName into DB_NAME.
+func snakeCase(s string) string {
+	var builder strings.Builder
+	for i, r := range s {
+		if i > 0 && unicode.IsUpper(r) {
+			builder.WriteByte('_')
+		}
+		builder.WriteRune(unicode.ToUpper(r))
+	}
+	return builder.String()
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+const envTestYaml = `
+server:
+  port: 5000
+database:
+  dbName: "arch_db"
+  password: ""
+rateLimit:
+  groups:
+    public:
+      limit: 60
+`
+
+func TestApplyEnv(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	assert.NoError(t, v.ReadConfig(strings.NewReader(envTestYaml)))
+
+	secretFile := filepath.Join(t.TempDir(), "password")
+	assert.NoError(t, os.WriteFile(secretFile, []byte("from-file\n"), 0o600))
+
+	t.Setenv("APP_SERVER_PORT", "8080")
+	t.Setenv("APP_SERVER_REQUEST_TIMEOUT", "10s")
+	t.Setenv("APP_DATABASE_DB_NAME", "other_db")
+	t.Setenv("APP_DATABASE_PASSWORD_FILE", secretFile)
+	t.Setenv("APP_CORS_ALLOWED_ORIGINS", "https://a.example.com,https://b.example.com")
+	t.Setenv("APP_RATE_LIMIT_GROUPS_PUBLIC_LIMIT", "5")
+	sources, err := applyEnv(v, configKeys(v))
+	assert.NoError(t, err)
+
+	var c Config
+	assert.NoError(t, v.Unmarshal(&c))
+	assert.Equal(t, 8080, c.Server.Port)
+	assert.Equal(t, 10*time.Second, c.Server.RequestTimeout)
+	assert.Equal(t, "other_db", c.Database.DbName)
+	assert.Equal(t, "from-file", c.Database.Password)
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, c.Cors.AllowedOrigins)
+	assert.Equal(t, 5, c.RateLimit.Groups["public"].Limit)
+	assert.Equal(t, "APP_DATABASE_PASSWORD_FILE", sources["database.password"])
+
+	t.Setenv("APP_DATABASE_PASSWORD", "from-env")
+	_, err = applyEnv(v, configKeys(v))
+	assert.ErrorContains(t, err, "both APP_DATABASE_PASSWORD and APP_DATABASE_PASSWORD_FILE are set")
+}
+
+func TestEnvKeys(t *testing.T) {
+	envs := map[string]string{}
+	for _, k := range envKeys(viper.New(), reflect.TypeOf(Config{}), "", envPrefix) {
+		envs[k.key] = k.env
+	}
+
+	assert.Equal(t, "APP_DATABASE_PASSWORD", envs["database.password"])
+	assert.Equal(t, "APP_SERVER_TLS_CERT_FILE", envs["server.tls.certFile"])
+	assert.Equal(t, "APP_ALERT_WEBHOOK_URL", envs["alert.webhookUrl"])
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+const (
+	DefaultDir = "config"
+
+	baseFile      = "config.yaml"
+	localFile     = "config.local.yaml" // untracked, for a developer machine
+	sourceDefault = "default"
+
+	reloadDebounce = 100 * time.Millisecond
+)
+
+var watchOnce sync.Once
+
+// Value is a key of the effective config with the file or environment variable that set it.
+type Value struct {
+	Key    string
+	Value  interface{}
+	Source string
+}
+
+// layerFiles lists the files of dir in merge order, a later file overrides an earlier one.
+// Only the base file is required.
+func layerFiles(dir, env string) []string {
+	return []string{
+		filepath.Join(dir, baseFile),
+		filepath.Join(dir, fmt.Sprintf("config-%s.yaml", env)),
+		filepath.Join(dir, localFile),
+	}
+}
+
+// readLayers merges the layer files and records the file setting each key.
+func readLayers(dir, env string) (*viper.Viper, map[string]string, error) {
+	v := viper.New()
+	sources := map[string]string{}
+
+	for i, file := range layerFiles(dir, env) {
+		if _, err := os.Stat(file); i > 0 && os.IsNotExist(err) {
+			continue
+		}
+
+		layer := viper.New()
+		layer.SetConfigFile(file)
+		if err := layer.ReadInConfig(); err != nil {
+			return nil, nil, fmt.Errorf("read %s: %w", file, err)
+		}
+		if err := v.MergeConfigMap(layer.AllSettings()); err != nil {
+			return nil, nil, fmt.Errorf("merge %s: %w", file, err)
+		}
+		for _, key := range layer.AllKeys() {
+			sources[key] = file
+		}
+	}
+
+	return v, sources, nil
+}
+
+// Effective loads the config of dir like InitFrom and lists every key with its value and source,
+// secrets are redacted.
+func Effective(dir, env string) ([]Value, error) {
+	_, m, err := loadMerged(dir, env)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []Value{}
+	for _, k := range m.keys {
+		source, ok := m.sources[strings.ToLower(k.key)]
+		if !ok {
+			source = sourceDefault
+		}
+
+		value := m.v.Get(k.key)
+		if k.secret && value != nil && fmt.Sprint(value) != "" {
+			value = redacted
+		}
+		values = append(values, Value{Key: k.key, Value: value, Source: source})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Key < values[j].Key
+	})
+	return values, nil
+}
+
+// watch reloads the config when a layer file of dir is written, created or removed. The directory
+// is watched so files replaced by editors or by a kubernetes ConfigMap update are picked up.
+func watch(dir, env string) (err error) {
+	watchOnce.Do(func() {
+		var watcher *fsnotify.Watcher
+		watcher, err = fsnotify.NewWatcher()
+		if err != nil {
+			return
+		}
+		if err = watcher.Add(dir); err != nil {
+			watcher.Close()
+			return
+		}
+
+		files := map[string]bool{}
+		for _, file := range layerFiles(dir, env) {
+			files[filepath.Clean(file)] = true
+		}
+
+		go func() {
+			var timer *time.Timer
+			for {
+				select {
+				case event, ok := <-watcher.Events:
+					if !ok {
+						return
+					}
+					// ..data is the symlink kubernetes swaps on a ConfigMap update
+					if !files[filepath.Clean(event.Name)] && filepath.Base(event.Name) != "..data" {
+						continue
+					}
+					// editors write a file in several events, reload once they are done
+					if timer != nil {
+						timer.Stop()
+					}
+					timer = time.AfterFunc(reloadDebounce, func() {
+						reload(dir, env)
+					})
+				case err, ok := <-watcher.Errors:
+					if !ok {
+						return
+					}
+					log.Printf("config watcher error %v", err)
+				}
+			}
+		}()
+	})
+	return err
+}
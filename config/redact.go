@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+const redacted = "[REDACTED]"
+
+// Redacted returns a copy of c with every non-empty string field tagged `secret:"true"` replaced,
+// use it whenever the config is logged or dumped.
+func Redacted(c Config) Config {
+	redact(reflect.ValueOf(&c).Elem())
+	return c
+}
+
+func redact(value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		structField := value.Type().Field(i)
+
+		switch {
+		case field.Kind() == reflect.Struct:
+			redact(field)
+		case field.Kind() == reflect.Map && field.Type().Elem().Kind() == reflect.Struct:
+			copied := reflect.MakeMapWithSize(field.Type(), field.Len())
+			iter := field.MapRange()
+			for iter.Next() {
+				item := reflect.New(field.Type().Elem()).Elem()
+				item.Set(iter.Value())
+				redact(item)
+				copied.SetMapIndex(iter.Key(), item)
+			}
+			field.Set(copied)
+		case structField.Tag.Get("secret") == "true" && field.Kind() == reflect.String && field.String() != "":
+			field.SetString(redacted)
+		}
+	}
+}
+
+// String methods keep secrets out of logs when the config is printed with %v or %+v.
+
+func (c Config) String() string {
+	type plain Config
+	return fmt.Sprintf("%+v", plain(c))
+}
+
+func (c DatabaseConfig) String() string {
+	type plain DatabaseConfig
+	p := plain(c)
+	return redactedString(&p)
+}
+
+func (c RedisConfig) String() string {
+	type plain RedisConfig
+	p := plain(c)
+	return redactedString(&p)
+}
+
+func (c AlertConfig) String() string {
+	type plain AlertConfig
+	p := plain(c)
+	return redactedString(&p)
+}
+
+func (c AuthConfig) String() string {
+	type plain AuthConfig
+	p := plain(c)
+	return redactedString(&p)
+}
+
+func (c AdminConfig) String() string {
+	type plain AdminConfig
+	p := plain(c)
+	return redactedString(&p)
+}
+
+// redactedString prints the struct pointed by v, its type must not have a String method.
+func redactedString(v interface{}) string {
+	value := reflect.ValueOf(v).Elem()
+	redact(value)
+	return fmt.Sprintf("%+v", value.Interface())
+}
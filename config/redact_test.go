@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedacted(t *testing.T) {
+	c := Config{
+		Database: DatabaseConfig{Username: "shenna", Password: "secret"},
+		Auth:     AuthConfig{Algorithm: "HS256", Secret: "signing-secret"},
+		Admin:    AdminConfig{Token: ""},
+	}
+
+	result := Redacted(c)
+
+	assert.Equal(t, "shenna", result.Database.Username)
+	assert.Equal(t, redacted, result.Database.Password)
+	assert.Equal(t, redacted, result.Auth.Secret)
+	assert.Equal(t, "", result.Admin.Token, "empty secrets stay empty")
+	assert.Equal(t, "secret", c.Database.Password, "the original is not modified")
+
+	printed := fmt.Sprintf("%v %+v", c, c.Auth)
+	assert.NotContains(t, printed, "secret,")
+	assert.NotContains(t, printed, "signing-secret")
+	assert.Contains(t, printed, "Password:"+redacted)
+}
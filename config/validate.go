@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/viper"
+)
+
+const storeRedis = "redis"
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	// report the yaml key instead of the Go field name
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return lowerFirst(field.Name)
+	})
+	v.RegisterStructValidation(validateConfig, Config{})
+	return v
+}
+
+// Validate reports every invalid value of c at once, each with its key path like server.port.
+func Validate(c Config) error {
+	err := validate.Struct(c)
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	messages := make([]string, len(validationErrors))
+	for i, e := range validationErrors {
+		messages[i] = fmt.Sprintf("%s %s", strings.TrimPrefix(e.Namespace(), "Config."), describe(e))
+	}
+	return fmt.Errorf("invalid config:\n  %s", strings.Join(messages, "\n  "))
+}
+
+// checkUnknownKeys rejects keys of v that are not in Config, usually a typo that would leave
+// the intended field empty. keys are the configKeys of v.
+func checkUnknownKeys(v *viper.Viper, keys []envKey) error {
+	known := map[string]bool{}
+	for _, k := range keys {
+		known[strings.ToLower(k.key)] = true
+	}
+
+	unknown := []string{}
+	for _, key := range v.AllKeys() {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown config keys: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// validateConfig checks the rules spanning several sections.
+func validateConfig(sl validator.StructLevel) {
+	c := sl.Current().Interface().(Config)
+
+	if c.Auth.Store == storeRedis || c.RateLimit.Store == storeRedis || c.Idempotency.Store == storeRedis {
+		if c.Redis.Host == "" {
+			sl.ReportError(c.Redis.Host, "redis.host", "Host", "required_with_redis_store", "")
+		}
+		if c.Redis.Port == "" {
+			sl.ReportError(c.Redis.Port, "redis.port", "Port", "required_with_redis_store", "")
+		}
+	}
+
+	if c.Cors.AllowCredentials && slices.Contains(c.Cors.AllowedOrigins, "*") {
+		sl.ReportError(c.Cors.AllowedOrigins, "cors.allowedOrigins", "AllowedOrigins", "any_origin_with_credentials", "")
+	}
+
+	// middleware.Timeout falls back to requestTimeout when it cannot extend the write deadline
+	// for a longer route timeout, a request without deadline would be cut at writeTimeout
+	if c.Server.WriteTimeout > 0 && c.Server.RequestTimeout == 0 {
+		sl.ReportError(c.Server.RequestTimeout, "server.requestTimeout", "RequestTimeout", "required_with_write_timeout", "")
+	}
+	if c.Server.WriteTimeout > 0 && c.Server.RequestTimeout > 0 && c.Server.WriteTimeout <= c.Server.RequestTimeout {
+		sl.ReportError(c.Server.WriteTimeout, "server.writeTimeout", "WriteTimeout", "gt_request_timeout", "")
+	}
+}
+
+// siblingKey is the key path of field next to the field of e, e.g. auth.algorithm for auth.secret.
+func siblingKey(e validator.FieldError, field string) string {
+	namespace := strings.TrimPrefix(e.Namespace(), "Config.")
+	if i := strings.LastIndex(namespace, "."); i >= 0 {
+		return namespace[:i+1] + lowerFirst(field)
+	}
+	return lowerFirst(field)
+}
+
+func describe(e validator.FieldError) string {
+	switch e.Tag() {
+	case "required":
+		return "is required"
+	case "required_if":
+		// the param lists field value pairs, e.g. Algorithm HS256
+		params := strings.Fields(e.Param())
+		conditions := []string{}
+		for i := 0; i+1 < len(params); i += 2 {
+			conditions = append(conditions, fmt.Sprintf("%s is %s", siblingKey(e, params[i]), params[i+1]))
+		}
+		return fmt.Sprintf("is required when %s", strings.Join(conditions, " and "))
+	case "required_with":
+		fields := strings.Fields(e.Param())
+		for i, field := range fields {
+			fields[i] = siblingKey(e, field)
+		}
+		return fmt.Sprintf("is required when %s is set", strings.Join(fields, " or "))
+	case "required_with_redis_store":
+		return "is required when auth.store, rateLimit.store or idempotency.store is redis"
+	case "any_origin_with_credentials":
+		return `cannot contain "*" when cors.allowCredentials is true, list the origins instead`
+	case "required_with_write_timeout":
+		return "is required when server.writeTimeout is set, the server cuts a response without deadline at server.writeTimeout"
+	case "gt_request_timeout":
+		return "must be greater than server.requestTimeout"
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s]", strings.Join(strings.Fields(e.Param()), ", "))
+	case "min":
+		return fmt.Sprintf("must be at least %s", e.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", e.Param())
+	case "gtfield":
+		return fmt.Sprintf("must be greater than %s", lowerFirst(e.Param()))
+	case "numeric":
+		return "must be a number"
+	case "http_url":
+		return "must be an http or https URL"
+	case "semver":
+		return "must be a semantic version like 1.4.2"
+	case "ip|cidr":
+		return "must be an IP address or CIDR"
+	}
+	return fmt.Sprintf("failed on %s %s", e.Tag(), e.Param())
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalConfigIsValid(t *testing.T) {
+	v, _, err := readLayers(".", "local")
+	assert.NoError(t, err)
+
+	var c Config
+	assert.NoError(t, checkUnknownKeys(v, configKeys(v)))
+	assert.NoError(t, v.Unmarshal(&c))
+	assert.NoError(t, Validate(c))
+}
+
+func TestValidateReportsEveryViolation(t *testing.T) {
+	c := Config{
+		Server:    ServerConfig{Host: "127.0.0.1", Port: 0, WriteTimeout: 35 * time.Second},
+		Log:       LogConfig{Level: "verbose"},
+		Alert:     AlertConfig{WebhookUrl: "chat.googleapis.com/hook"},
+		Auth:      AuthConfig{Algorithm: "HS256", Issuer: "arch-pba", Store: "redis"},
+		RateLimit: RateLimitConfig{Store: "memory", Groups: map[string]RateLimitRule{"public": {Limit: -1}}},
+		Client:    ClientConfig{MinVersions: map[string]string{"android": "one"}},
+		Cors:      CorsConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+	}
+
+	err := Validate(c)
+
+	assert.Error(t, err)
+	for _, expected := range []string{
+		"server.port must be at least 1",
+		"database.host is required",
+		"log.level must be one of [trace, debug",
+		"alert.webhookUrl must be an http or https URL",
+		"auth.secret is required when auth.algorithm is HS256",
+		"rateLimit.groups[public].limit must be at least 0",
+		"client.minVersions[android] must be a semantic version",
+		"redis.host is required when auth.store, rateLimit.store or idempotency.store is redis",
+		`cors.allowedOrigins cannot contain "*" when cors.allowCredentials is true`,
+		"server.requestTimeout is required when server.writeTimeout is set",
+	} {
+		assert.Contains(t, err.Error(), expected)
+	}
+	assert.NotContains(t, err.Error(), "Config.")
+}
+
+func TestValidateDescribesRequiredConditions(t *testing.T) {
+	c := Config{
+		Server: ServerConfig{Tls: TlsConfig{CertFile: "tls.crt"}},
+		Auth:   AuthConfig{Algorithm: "RS256"},
+		Admin:  AdminConfig{Enabled: true},
+	}
+
+	messages := strings.Split(Validate(c).Error(), "\n  ")
+
+	assert.Contains(t, messages, "auth.privateKeyFile is required when auth.algorithm is RS256")
+	assert.Contains(t, messages, "admin.host is required when admin.enabled is true")
+	assert.Contains(t, messages, "server.tls.keyFile is required when server.tls.certFile is set")
+	assert.NotContains(t, Validate(c).Error(), "auth.secret")
+}
+
+func TestUnknownKeysAreRejected(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	assert.NoError(t, v.ReadConfig(strings.NewReader("server:\n  prot: 5000\nrateLimit:\n  groups:\n    public:\n      limt: 60\n")))
+
+	assert.EqualError(t, checkUnknownKeys(v, configKeys(v)), "unknown config keys: ratelimit.groups.public.limt, server.prot")
+}
@@ -2,47 +2,114 @@ package database
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
 	"fmt"
 	"log"
+	"net"
+	"os"
+	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	config "github.com/mochammadshenna/arch-pba-template/config"
 	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
 )
 
+const tlsConfigName = "database-ca" // registered TLS config of database.tlsCaFile
+
 func NewDB() *sql.DB {
 	return newDb(config.Get().Database.DbName)
 }
 
+// Open connects to the configured database and returns the error instead of exiting.
+func Open() (*sql.DB, error) {
+	return open(config.Get().Database.DbName)
+}
+
 func newDb(dbName string) *sql.DB {
-	var dbConfig = config.Get().Database
+	db, err := open(dbName)
+	if err != nil {
+		logger.Fatal(context.TODO(), err)
+	}
+	return db
+}
 
-	mysqlInfo := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s",
-		dbConfig.Username,
-		dbConfig.Password,
-		dbConfig.Host,
-		dbConfig.Port,
-		dbName,
-	)
+func open(dbName string) (*sql.DB, error) {
+	var dbConfig = config.Get().Database
 
-	// mysqlInfo := "shenna:Aqilah@21@tcp(localhost:3306)/arch_db"
+	mysqlInfo, err := dsn(dbConfig, dbName)
+	if err != nil {
+		return nil, err
+	}
 
 	db, err := sql.Open("mysql", mysqlInfo)
 	panicOnError(err)
 	if err = db.Ping(); err != nil {
-		logger.Fatal(context.TODO(), err)
-		if err = db.Close(); err != nil {
-			logger.Fatal(context.TODO(), err)
+		db.Close()
+		return nil, fmt.Errorf("ping mysql %s:%s/%s: %w", dbConfig.Host, dbConfig.Port, dbName, err)
+	}
+
+	db.SetMaxOpenConns(dbConfig.MaxOpenConns)
+	db.SetMaxIdleConns(dbConfig.MaxIdleConns)
+	db.SetConnMaxLifetime(dbConfig.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(dbConfig.ConnMaxIdleTime)
+
+	return db, nil
+}
+
+// dsn builds the driver DSN from the database config, the password is escaped by the driver.
+func dsn(dbConfig config.DatabaseConfig, dbName string) (string, error) {
+	mysqlConfig := mysql.NewConfig()
+	mysqlConfig.User = dbConfig.Username
+	mysqlConfig.Passwd = dbConfig.Password
+	mysqlConfig.Net = "tcp"
+	mysqlConfig.Addr = net.JoinHostPort(dbConfig.Host, dbConfig.Port)
+	mysqlConfig.DBName = dbName
+	mysqlConfig.ParseTime = dbConfig.ParseTime
+	mysqlConfig.Timeout = dbConfig.DialTimeout
+	mysqlConfig.ReadTimeout = dbConfig.ReadTimeout
+	mysqlConfig.WriteTimeout = dbConfig.WriteTimeout
+
+	if dbConfig.Loc != "" {
+		loc, err := time.LoadLocation(dbConfig.Loc)
+		if err != nil {
+			return "", fmt.Errorf("database.loc: %w", err)
+		}
+		mysqlConfig.Loc = loc
+	}
+	if dbConfig.Charset != "" {
+		mysqlConfig.Params = map[string]string{"charset": dbConfig.Charset}
+	}
+
+	mysqlConfig.TLSConfig = dbConfig.Tls
+	if dbConfig.TlsCaFile != "" {
+		// FormatDSN only keeps the name of a registered TLS config
+		tlsConfig, err := caTlsConfig(dbConfig.TlsCaFile)
+		if err != nil {
+			return "", err
+		}
+		if err := mysql.RegisterTLSConfig(tlsConfigName, tlsConfig); err != nil {
+			return "", err
 		}
+		mysqlConfig.TLSConfig = tlsConfigName
 	}
 
-	// db.SetMaxIdleConns(5)
-	// db.SetMaxOpenConns(20)
-	// db.SetConnMaxLifetime(60 * time.Minute)
-	// db.SetConnMaxIdleTime(10 * time.Minute)
+	return mysqlConfig.FormatDSN(), nil
+}
 
-	return db
+// caTlsConfig verifies the server with the CA of caFile instead of the system roots,
+// the server name is set by the driver from the host.
+func caTlsConfig(caFile string) (*tls.Config, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("database.tlsCaFile: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("database.tlsCaFile: no certificate found in %s", caFile)
+	}
+	return &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}, nil
 }
 
 func panicOnError(err error) {
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/amacneil/dbmate/v2/pkg/dbmate"
+	_ "github.com/amacneil/dbmate/v2/pkg/driver/mysql"
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/health"
+)
+
+const migrationsDir = "./scripts/migrations"
+
+// NewMigrator returns dbmate for the configured database.
+func NewMigrator() *dbmate.DB {
+	dbConfig := config.Get().Database
+
+	u := &url.URL{
+		Scheme: "mysql",
+		User:   url.UserPassword(dbConfig.Username, dbConfig.Password),
+		Host:   fmt.Sprintf("%s:%s", dbConfig.Host, dbConfig.Port),
+		Path:   "/" + dbConfig.DbName,
+	}
+
+	db := dbmate.New(u)
+	db.MigrationsDir = []string{migrationsDir}
+	return db
+}
+
+// PendingMigrations counts the migration files not applied yet, like dbmate status.
+func PendingMigrations(migrator *dbmate.DB) (int, error) {
+	migrations, err := migrator.FindMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, migration := range migrations {
+		if !migration.Applied {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// MigrationCheck fails readiness while migrations are pending, the pod waits for dbmate up.
+func MigrationCheck(migrator *dbmate.DB) health.Check {
+	return func(ctx context.Context) error {
+		pending, err := PendingMigrations(migrator)
+		if err != nil {
+			return err
+		}
+		if pending > 0 {
+			return fmt.Errorf("%d pending migrations", pending)
+		}
+		return nil
+	}
+}
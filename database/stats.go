@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	statsDb      atomic.Pointer[sql.DB]
+	publishStats sync.Once
+)
+
+// PoolStats is sql.DBStats with the wait counters of the last interval.
+type PoolStats struct {
+	MaxOpenConnections int   `json:"maxOpenConnections"`
+	OpenConnections    int   `json:"openConnections"`
+	InUse              int   `json:"inUse"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"waitCount"`
+	WaitDurationMs     int64 `json:"waitDurationMs"`
+	MaxIdleClosed      int64 `json:"maxIdleClosed"`
+	MaxIdleTimeClosed  int64 `json:"maxIdleTimeClosed"`
+	MaxLifetimeClosed  int64 `json:"maxLifetimeClosed"`
+	IntervalWaitCount  int64 `json:"intervalWaitCount"`
+	IntervalWaitMs     int64 `json:"intervalWaitMs"`
+}
+
+// StatsReporter logs the pool stats of db every interval and warns when more than waitCountWarn
+// connections were waited for since the previous report, the pool is too small for the load.
+// The live stats are published as the expvar "mysql", see /debug/vars on the admin server.
+type StatsReporter struct {
+	db            *sql.DB
+	interval      time.Duration
+	waitCountWarn int64
+	last          sql.DBStats
+	stop          chan struct{}
+	done          chan struct{}
+}
+
+func NewStatsReporter(db *sql.DB, interval time.Duration, waitCountWarn int64) *StatsReporter {
+	statsDb.Store(db)
+	publishStats.Do(func() {
+		expvar.Publish("mysql", expvar.Func(func() interface{} {
+			if db := statsDb.Load(); db != nil {
+				return poolStats(db.Stats(), sql.DBStats{})
+			}
+			return nil
+		}))
+	})
+
+	return &StatsReporter{
+		db:            db,
+		interval:      interval,
+		waitCountWarn: waitCountWarn,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// Start reports every interval until Stop, an interval of 0 only publishes the expvar.
+func (r *StatsReporter) Start() {
+	if r.interval <= 0 {
+		close(r.done)
+		return
+	}
+
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.report(context.Background())
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the reports, it matches lifecycle.StopFunc.
+func (r *StatsReporter) Stop(ctx context.Context) error {
+	close(r.stop)
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *StatsReporter) report(ctx context.Context) PoolStats {
+	current := r.db.Stats()
+	stats := poolStats(current, r.last)
+	r.last = current
+
+	entry := logger.WithFields(ctx, stats.fields())
+	if r.waitCountWarn > 0 && stats.IntervalWaitCount > r.waitCountWarn {
+		entry.Warnf("mysql pool waited for %d connections (%dms) in the last %s, in use %d/%d",
+			stats.IntervalWaitCount, stats.IntervalWaitMs, r.interval, stats.InUse, stats.MaxOpenConnections)
+		return stats
+	}
+	entry.Info("mysql pool stats")
+	return stats
+}
+
+func poolStats(current, last sql.DBStats) PoolStats {
+	return PoolStats{
+		MaxOpenConnections: current.MaxOpenConnections,
+		OpenConnections:    current.OpenConnections,
+		InUse:              current.InUse,
+		Idle:               current.Idle,
+		WaitCount:          current.WaitCount,
+		WaitDurationMs:     current.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      current.MaxIdleClosed,
+		MaxIdleTimeClosed:  current.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  current.MaxLifetimeClosed,
+		IntervalWaitCount:  current.WaitCount - last.WaitCount,
+		IntervalWaitMs:     (current.WaitDuration - last.WaitDuration).Milliseconds(),
+	}
+}
+
+func (s PoolStats) fields() logrus.Fields {
+	return logrus.Fields{
+		"maxOpenConnections": s.MaxOpenConnections,
+		"openConnections":    s.OpenConnections,
+		"inUse":              s.InUse,
+		"idle":               s.Idle,
+		"waitCount":          s.WaitCount,
+		"waitDurationMs":     s.WaitDurationMs,
+		"maxIdleClosed":      s.MaxIdleClosed,
+		"maxIdleTimeClosed":  s.MaxIdleTimeClosed,
+		"maxLifetimeClosed":  s.MaxLifetimeClosed,
+		"intervalWaitCount":  s.IntervalWaitCount,
+		"intervalWaitMs":     s.IntervalWaitMs,
+	}
+}
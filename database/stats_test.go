@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoolStatsReportsIntervalWaits(t *testing.T) {
+	last := sql.DBStats{WaitCount: 10, WaitDuration: time.Second}
+	current := sql.DBStats{MaxOpenConnections: 20, InUse: 20, WaitCount: 25, WaitDuration: 3 * time.Second}
+
+	stats := poolStats(current, last)
+
+	assert.Equal(t, int64(25), stats.WaitCount)
+	assert.Equal(t, int64(15), stats.IntervalWaitCount)
+	assert.Equal(t, int64(2000), stats.IntervalWaitMs)
+	assert.Equal(t, 20, stats.InUse)
+}
+
+func TestStatsReporterStops(t *testing.T) {
+	// sql.Open does not connect
+	db, err := sql.Open("mysql", "user:password@tcp(127.0.0.1:1)/test")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	reporter := NewStatsReporter(db, time.Millisecond, 1)
+	reporter.Start()
+	time.Sleep(5 * time.Millisecond)
+
+	assert.NoError(t, reporter.Stop(context.Background()))
+	assert.Equal(t, int64(0), reporter.report(context.Background()).IntervalWaitCount)
+}
+
+func TestDsn(t *testing.T) {
+	dbConfig := config.DatabaseConfig{
+		Host:         "db.internal",
+		Port:         "3306",
+		Username:     "user",
+		Password:     "p@ss",
+		ParseTime:    true,
+		Loc:          "Asia/Jakarta",
+		Charset:      "utf8mb4",
+		DialTimeout:  5 * time.Second,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+		Tls:          "skip-verify",
+	}
+
+	mysqlInfo, err := dsn(dbConfig, "arch_db")
+	assert.NoError(t, err)
+	parsed, err := mysql.ParseDSN(mysqlInfo)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "p@ss", parsed.Passwd)
+	assert.Equal(t, "db.internal:3306", parsed.Addr)
+	assert.Equal(t, "arch_db", parsed.DBName)
+	assert.True(t, parsed.ParseTime)
+	assert.Equal(t, "Asia/Jakarta", parsed.Loc.String())
+	assert.Equal(t, map[string]string{"charset": "utf8mb4"}, parsed.Params)
+	assert.Equal(t, 5*time.Second, parsed.Timeout)
+	assert.Equal(t, 30*time.Second, parsed.ReadTimeout)
+	assert.Equal(t, "skip-verify", parsed.TLSConfig)
+
+	dbConfig.Loc = "Mars/Olympus"
+	_, err = dsn(dbConfig, "arch_db")
+	assert.ErrorContains(t, err, "database.loc")
+}
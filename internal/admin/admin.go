@@ -0,0 +1,172 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+	"time"
+
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	router "github.com/mochammadshenna/arch-pba-template/internal/routes"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var startedAt = time.Now()
+
+type RuntimeStats struct {
+	GoVersion     string `json:"goVersion"`
+	Environment   string `json:"environment"`
+	UptimeSeconds int64  `json:"uptimeSeconds"`
+	NumCpu        int    `json:"numCpu"`
+	NumGoroutine  int    `json:"numGoroutine"`
+	HeapAlloc     uint64 `json:"heapAlloc"`
+	HeapInuse     uint64 `json:"heapInuse"`
+	HeapObjects   uint64 `json:"heapObjects"`
+	Sys           uint64 `json:"sys"`
+	NumGc         uint32 `json:"numGc"`
+	PauseTotalNs  uint64 `json:"pauseTotalNs"`
+}
+
+type logLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// NewServer returns the admin server of admin.host and admin.port. It must be bound to a loopback
+// host or protected by admin.token, pprof and the config dump are never exposed publicly.
+func NewServer(adminConfig config.AdminConfig, routes *router.Router) (*http.Server, error) {
+	loopback := isLoopback(adminConfig.Host)
+	if !loopback && adminConfig.Token == "" {
+		return nil, fmt.Errorf("admin.token is required when admin.host %q is not a loopback address", adminConfig.Host)
+	}
+
+	return &http.Server{
+		Addr:              fmt.Sprintf("%s:%d", adminConfig.Host, adminConfig.Port),
+		Handler:           protect(NewHandler(routes), adminConfig.Token),
+		ReadHeaderTimeout: 5 * time.Second,
+	}, nil
+}
+
+// NewHandler serves the admin endpoints:
+//
+//	/debug/pprof/  net/http/pprof
+//	/debug/vars    expvar, including the mysql pool stats
+//	/config        effective config with secrets redacted
+//	/runtime       runtime stats
+//	/routes        registered routes and the API versions serving them
+//	/log/level     GET the log level, PUT {"level":"debug"} to change it until log.level changes in the config
+func NewHandler(routes *router.Router) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	mux.HandleFunc("/config", func(writer http.ResponseWriter, request *http.Request) {
+		writeJson(writer, http.StatusOK, config.Redacted(*config.Get()))
+	})
+	mux.HandleFunc("/runtime", func(writer http.ResponseWriter, request *http.Request) {
+		writeJson(writer, http.StatusOK, runtimeStats())
+	})
+	mux.HandleFunc("/routes", func(writer http.ResponseWriter, request *http.Request) {
+		writeJson(writer, http.StatusOK, map[string]interface{}{
+			"routes":   routes.Routes(),
+			"versions": routes.Versions(),
+		})
+	})
+	mux.HandleFunc("/log/level", logLevel)
+
+	return mux
+}
+
+func logLevel(writer http.ResponseWriter, request *http.Request) {
+	switch request.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		body := logLevelRequest{}
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			writeJson(writer, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		level, err := logrus.ParseLevel(body.Level)
+		if err != nil {
+			writeJson(writer, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		logger.Warnf(context.Background(), "log level changed from %s to %s through the admin server", logger.Logger.GetLevel(), level)
+		logger.Logger.SetLevel(level)
+	default:
+		writer.Header().Set("Allow", "GET, PUT")
+		writeJson(writer, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	writeJson(writer, http.StatusOK, logLevelRequest{Level: logger.Logger.GetLevel().String()})
+}
+
+func runtimeStats() RuntimeStats {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	return RuntimeStats{
+		GoVersion:     runtime.Version(),
+		Environment:   state.App.Environment,
+		UptimeSeconds: int64(time.Since(startedAt).Seconds()),
+		NumCpu:        runtime.NumCPU(),
+		NumGoroutine:  runtime.NumGoroutine(),
+		HeapAlloc:     memStats.HeapAlloc,
+		HeapInuse:     memStats.HeapInuse,
+		HeapObjects:   memStats.HeapObjects,
+		Sys:           memStats.Sys,
+		NumGc:         memStats.NumGC,
+		PauseTotalNs:  memStats.PauseTotalNs,
+	}
+}
+
+// protect requires the bearer token, or a loopback caller when no token is configured.
+func protect(next http.Handler, token string) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if token == "" {
+			host, _, _ := net.SplitHostPort(request.RemoteAddr)
+			if !isLoopback(host) {
+				writeJson(writer, http.StatusForbidden, map[string]string{"error": "admin server is loopback only"})
+				return
+			}
+			next.ServeHTTP(writer, request)
+			return
+		}
+
+		scheme, value, _ := strings.Cut(request.Header.Get(state.HttpHeaders().Authorization.String()), " ")
+		if !strings.EqualFold(scheme, "Bearer") || subtle.ConstantTimeCompare([]byte(value), []byte(token)) != 1 {
+			writeJson(writer, http.StatusUnauthorized, map[string]string{"error": "invalid admin token"})
+			return
+		}
+		next.ServeHTTP(writer, request)
+	})
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func writeJson(writer http.ResponseWriter, status int, data interface{}) {
+	writer.Header().Set(state.HttpHeaders().ContentType.String(), state.HttpContentTypeValues().ApplicationJson)
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(data)
+}
@@ -0,0 +1,54 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	router "github.com/mochammadshenna/arch-pba-template/internal/routes"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewServerRequiresTokenOutsideLoopback(t *testing.T) {
+	_, err := NewServer(config.AdminConfig{Host: "0.0.0.0", Port: 5001}, router.New())
+	assert.ErrorContains(t, err, "admin.token is required")
+
+	_, err = NewServer(config.AdminConfig{Host: "127.0.0.1", Port: 5001}, router.New())
+	assert.NoError(t, err)
+}
+
+func TestProtect(t *testing.T) {
+	handler := protect(NewHandler(router.New()), "admin-token")
+
+	request := httptest.NewRequest(http.MethodGet, "/runtime", nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	request.Header.Set("Authorization", "Bearer admin-token")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	loopbackOnly := protect(NewHandler(router.New()), "")
+	request = httptest.NewRequest(http.MethodGet, "/runtime", nil)
+	request.RemoteAddr = "10.0.0.5:4312"
+	recorder = httptest.NewRecorder()
+	loopbackOnly.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestLogLevel(t *testing.T) {
+	defer logger.Logger.SetLevel(logger.Logger.GetLevel())
+
+	recorder := httptest.NewRecorder()
+	NewHandler(router.New()).ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"level":"warn"}`)))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"level":"warning"}`, recorder.Body.String())
+	assert.Equal(t, logrus.WarnLevel, logger.Logger.GetLevel())
+}
@@ -0,0 +1,372 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/amacneil/dbmate/v2/pkg/dbmate"
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/mochammadshenna/arch-pba-template/database"
+	"github.com/mochammadshenna/arch-pba-template/internal/admin"
+	"github.com/mochammadshenna/arch-pba-template/internal/controller"
+	"github.com/mochammadshenna/arch-pba-template/internal/middleware"
+	"github.com/mochammadshenna/arch-pba-template/internal/outbound"
+	"github.com/mochammadshenna/arch-pba-template/internal/repository"
+	router "github.com/mochammadshenna/arch-pba-template/internal/routes"
+	"github.com/mochammadshenna/arch-pba-template/internal/service"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/alert"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/certificate"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/health"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/idempotency"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/lifecycle"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/ratelimit"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/token"
+	validators "github.com/mochammadshenna/arch-pba-template/internal/util/validator"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+const storeRedis = "redis"
+
+// Options selects the environment and replaces dependencies, a nil dependency is built from config.
+// Tests pass a Config and fakes so nothing connects to MySQL or Redis.
+type Options struct {
+	Environment string
+	ConfigDir   string // config.DefaultDir when empty
+	Config      *config.Config
+
+	DB       *sql.DB
+	Redis    *redis.Client
+	Migrator *dbmate.DB // with DB set, the migrations readiness check is only registered when given
+
+	TokenStore       token.Store
+	RateLimitStore   ratelimit.Store
+	IdempotencyStore idempotency.Store
+	PbaController    controller.PbaController // the brand routes are not served without one, see router.NewRouter
+}
+
+type Repositories struct {
+	User repository.UserRepository
+	Role repository.RoleRepository
+}
+
+type Services struct {
+	Auth service.AuthService
+}
+
+type Controllers struct {
+	Pba    controller.PbaController
+	Auth   controller.AuthController
+	Health controller.HealthController
+}
+
+// Container holds the application dependencies, built once in dependency order by Build.
+type Container struct {
+	options Options
+
+	Lifecycle        *lifecycle.Manager
+	Health           *health.Registry
+	DB               *sql.DB
+	Redis            *redis.Client // nil when no store uses redis
+	TokenManager     *token.Manager
+	TokenStore       token.Store
+	RateLimitStore   ratelimit.Store
+	IdempotencyStore idempotency.Store
+	Repositories     Repositories
+	Services         Services
+	Controllers      Controllers
+	Router           *router.Router
+	Server           *http.Server
+	AdminServer      *http.Server // nil unless admin.enabled
+}
+
+type step struct {
+	name  string
+	build func(c *Container) error
+}
+
+// steps builds the dependencies in order, a new resource gets a build func and a line here
+// after the steps it depends on.
+var steps = []step{
+	{"config", (*Container).buildConfig},
+	{"logger", (*Container).buildLogger},
+	{"validator", (*Container).buildValidator},
+	{"lifecycle", (*Container).buildLifecycle},
+	{"database", (*Container).buildDatabase},
+	{"redis", (*Container).buildRedis},
+	{"alert", (*Container).buildAlert},
+	{"health", (*Container).buildHealth},
+	{"stores", (*Container).buildStores},
+	{"token", (*Container).buildToken},
+	{"repositories", (*Container).buildRepositories},
+	{"services", (*Container).buildServices},
+	{"controllers", (*Container).buildControllers},
+	{"router", (*Container).buildRouter},
+	{"server", (*Container).buildServer},
+	{"admin", (*Container).buildAdmin},
+}
+
+// Build runs every step, the first failing step is returned as "build <step>: <err>".
+// Components started before the failure are stopped through the lifecycle manager.
+func Build(options Options) (*Container, error) {
+	c := &Container{options: options}
+
+	for _, s := range steps {
+		if err := s.build(c); err != nil {
+			if c.Lifecycle != nil {
+				_ = c.Lifecycle.Stop(context.Background())
+			}
+			return nil, fmt.Errorf("build %s: %w", s.name, err)
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Container) buildConfig() (err error) {
+	// config.InitFrom panics on a missing or malformed file
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	if c.options.Config != nil {
+		config.Set(*c.options.Config)
+		return nil
+	}
+
+	dir := c.options.ConfigDir
+	if dir == "" {
+		dir = config.DefaultDir
+	}
+	config.InitFrom(dir, c.options.Environment)
+	return nil
+}
+
+func (c *Container) buildLogger() error {
+	logger.Init()
+	return nil
+}
+
+func (c *Container) buildValidator() error {
+	validators.New()
+	return nil
+}
+
+func (c *Container) buildLifecycle() error {
+	c.Lifecycle = lifecycle.New()
+	c.Lifecycle.SetDrainDelay(config.Get().Server.DrainDelay)
+	return nil
+}
+
+func (c *Container) buildDatabase() error {
+	if c.options.DB != nil {
+		c.DB = c.options.DB
+		return nil
+	}
+
+	db, err := database.Open()
+	if err != nil {
+		return err
+	}
+	c.DB = db
+	c.Lifecycle.AppendCloser("mysql", db)
+
+	statsConfig := config.Get().Database.Stats
+	stats := database.NewStatsReporter(db, statsConfig.Interval, statsConfig.WaitCountWarn)
+	stats.Start()
+	c.Lifecycle.Append("mysql pool stats", stats.Stop)
+	return nil
+}
+
+func (c *Container) buildRedis() error {
+	if c.options.Redis != nil {
+		c.Redis = c.options.Redis
+		return nil
+	}
+
+	cfg := config.Get()
+	if cfg.Auth.Store != storeRedis && cfg.RateLimit.Store != storeRedis && cfg.Idempotency.Store != storeRedis {
+		return nil
+	}
+
+	client, err := outbound.OpenRedis()
+	if err != nil {
+		return err
+	}
+	c.Redis = client
+	c.Lifecycle.AppendCloser("redis", client)
+	return nil
+}
+
+// buildAlert is registered after the connections so pending alerts are flushed before they close.
+func (c *Container) buildAlert() error {
+	c.Lifecycle.Append("pending alerts", alert.Wait)
+	return nil
+}
+
+func (c *Container) buildHealth() error {
+	healthConfig := config.Get().Health
+	c.Health = health.NewRegistry(healthConfig.CheckTimeout, healthConfig.CacheTtl, c.Lifecycle.ShuttingDown)
+
+	c.Health.Register("mysql", c.DB.PingContext)
+	if c.Redis != nil {
+		c.Health.Register("redis", outbound.RedisCheck(c.Redis))
+	}
+
+	// a fake DB comes without migrations, the check only runs against the configured database or a given Migrator
+	migrator := c.options.Migrator
+	if migrator == nil && c.options.DB == nil {
+		migrator = database.NewMigrator()
+	}
+	if migrator != nil {
+		c.Health.Register("migrations", database.MigrationCheck(migrator))
+	}
+	return nil
+}
+
+func (c *Container) buildStores() error {
+	cfg := config.Get()
+
+	c.TokenStore = c.options.TokenStore
+	if c.TokenStore == nil {
+		if cfg.Auth.Store == storeRedis {
+			c.TokenStore = outbound.NewTokenStore(c.Redis)
+		} else {
+			c.TokenStore = token.NewMemoryStore()
+		}
+	}
+
+	c.RateLimitStore = c.options.RateLimitStore
+	if c.RateLimitStore == nil {
+		if cfg.RateLimit.Store == storeRedis {
+			c.RateLimitStore = outbound.NewRateLimitStore(c.Redis)
+		} else {
+			c.RateLimitStore = ratelimit.NewMemoryStore()
+		}
+	}
+
+	c.IdempotencyStore = c.options.IdempotencyStore
+	if c.IdempotencyStore == nil {
+		if cfg.Idempotency.Store == storeRedis {
+			c.IdempotencyStore = outbound.NewIdempotencyStore(c.Redis)
+		} else {
+			c.IdempotencyStore = idempotency.NewMemoryStore()
+		}
+	}
+	return nil
+}
+
+func (c *Container) buildToken() error {
+	manager, err := token.NewManager(config.Get().Auth)
+	if err != nil {
+		return err
+	}
+	c.TokenManager = manager
+	return nil
+}
+
+func (c *Container) buildRepositories() error {
+	c.Repositories = Repositories{
+		User: repository.NewUserRepository(),
+		Role: repository.NewRoleRepository(),
+	}
+	return nil
+}
+
+func (c *Container) buildServices() error {
+	c.Services = Services{
+		Auth: service.NewAuthService(c.DB, c.Repositories.User, c.Repositories.Role, c.TokenManager, c.TokenStore),
+	}
+	return nil
+}
+
+func (c *Container) buildControllers() error {
+	c.Controllers = Controllers{
+		Pba:    c.options.PbaController,
+		Auth:   controller.NewAuthController(c.Services.Auth),
+		Health: controller.NewHealthController(c.Health),
+	}
+	return nil
+}
+
+func (c *Container) buildRouter() error {
+	if c.Controllers.Pba == nil {
+		logger.Warn(context.Background(), "no PbaController implementation, /api/v1/brand is not served")
+	}
+	c.Router = router.NewRouter(
+		c.Controllers.Pba,
+		c.Controllers.Auth,
+		c.Controllers.Health,
+		c.RateLimitStore,
+		c.IdempotencyStore,
+		middleware.Authenticate(c.TokenManager, c.TokenStore),
+	)
+	return nil
+}
+
+func (c *Container) buildServer() error {
+	serverConfig := config.Get().Server
+	c.Server = &http.Server{
+		Addr:              fmt.Sprintf("%s:%d", serverConfig.Host, serverConfig.Port),
+		Handler:           c.Router,
+		ReadHeaderTimeout: serverConfig.ReadHeaderTimeout,
+		ReadTimeout:       serverConfig.ReadTimeout,
+		WriteTimeout:      serverConfig.WriteTimeout,
+		IdleTimeout:       serverConfig.IdleTimeout,
+		MaxHeaderBytes:    serverConfig.MaxHeaderBytes,
+	}
+
+	tlsConfig := serverConfig.Tls
+	if tlsConfig.CertFile == "" || tlsConfig.KeyFile == "" {
+		if serverConfig.H2c {
+			c.Server.Handler = h2c.NewHandler(c.Router, &http2.Server{IdleTimeout: serverConfig.IdleTimeout})
+		}
+		return nil
+	}
+
+	minVersion, err := certificate.TlsVersion(tlsConfig.MinVersion)
+	if err != nil {
+		return err
+	}
+	reloader, err := certificate.NewReloader(tlsConfig.CertFile, tlsConfig.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	// HTTP/2 is negotiated through ALPN by net/http
+	c.Server.TLSConfig = &tls.Config{
+		MinVersion:     minVersion,
+		GetCertificate: reloader.GetCertificate,
+	}
+	return nil
+}
+
+func (c *Container) buildAdmin() error {
+	adminConfig := config.Get().Admin
+	if !adminConfig.Enabled {
+		return nil
+	}
+
+	server, err := admin.NewServer(adminConfig, c.Router)
+	if err != nil {
+		return err
+	}
+	c.AdminServer = server
+	return nil
+}
+
+// Servers returns the servers to run, the api server first.
+func (c *Container) Servers() []*http.Server {
+	servers := []*http.Server{c.Server}
+	if c.AdminServer != nil {
+		servers = append(servers, c.AdminServer)
+	}
+	return servers
+}
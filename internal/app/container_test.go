@@ -0,0 +1,63 @@
+package app
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func testConfig() config.Config {
+	return config.Config{
+		Server: config.ServerConfig{Host: "127.0.0.1", Port: 5000},
+		Log:    config.LogConfig{Level: "error"},
+		Auth: config.AuthConfig{
+			Algorithm: "HS256",
+			Secret:    "test-secret",
+			Store:     "memory",
+		},
+		RateLimit: config.RateLimitConfig{Store: "memory"},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	// sql.Open does not connect, the container is built without a database
+	db, err := sql.Open("mysql", "user:password@tcp(127.0.0.1:1)/test")
+	assert.NoError(t, err)
+
+	cfg := testConfig()
+	container, err := Build(Options{Config: &cfg, DB: db})
+	assert.NoError(t, err)
+	assert.Nil(t, container.Redis)
+	assert.Equal(t, "127.0.0.1:5000", container.Server.Addr)
+
+	names := []string{}
+	for _, route := range container.Router.Routes() {
+		names = append(names, route.Name)
+	}
+	assert.Contains(t, names, "auth.login")
+	assert.Contains(t, names, "health.readiness")
+	assert.NotContains(t, names, "brand.list")
+
+	recorder := httptest.NewRecorder()
+	container.Router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// the fake DB gets no migrations check, nothing reads the database config
+	recorder = httptest.NewRecorder()
+	container.Router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Contains(t, recorder.Body.String(), `"mysql"`)
+	assert.NotContains(t, recorder.Body.String(), `"migrations"`)
+}
+
+func TestBuildReportsFailingStep(t *testing.T) {
+	db, _ := sql.Open("mysql", "user:password@tcp(127.0.0.1:1)/test")
+	cfg := testConfig()
+	cfg.Auth.Algorithm = "none"
+
+	_, err := Build(Options{Config: &cfg, DB: db})
+	assert.ErrorContains(t, err, `build token: unsupported auth.algorithm "none"`)
+}
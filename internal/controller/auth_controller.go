@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mochammadshenna/arch-pba-template/internal/model/api"
+	"github.com/mochammadshenna/arch-pba-template/internal/service"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/httphelper"
+	validators "github.com/mochammadshenna/arch-pba-template/internal/util/validator"
+)
+
+type AuthController interface {
+	Login(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Refresh(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Logout(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+}
+
+type AuthControllerImpl struct {
+	AuthService service.AuthService
+}
+
+func NewAuthController(authService service.AuthService) AuthController {
+	return &AuthControllerImpl{
+		AuthService: authService,
+	}
+}
+
+func (controller *AuthControllerImpl) Login(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	loginRequest := api.LoginRequest{}
+	if err := bindAndValidate(request, params, &loginRequest); err != nil {
+		httphelper.WriteError(request.Context(), writer, err)
+		return
+	}
+
+	response := controller.AuthService.Login(request.Context(), loginRequest)
+	httphelper.Write(request.Context(), writer, response)
+}
+
+func (controller *AuthControllerImpl) Refresh(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	refreshRequest := api.RefreshTokenRequest{}
+	if err := bindAndValidate(request, params, &refreshRequest); err != nil {
+		httphelper.WriteError(request.Context(), writer, err)
+		return
+	}
+
+	response := controller.AuthService.Refresh(request.Context(), refreshRequest)
+	httphelper.Write(request.Context(), writer, response)
+}
+
+func (controller *AuthControllerImpl) Logout(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	logoutRequest := api.LogoutRequest{}
+	if err := httphelper.Bind(request, params, &logoutRequest); err != nil {
+		httphelper.WriteError(request.Context(), writer, err)
+		return
+	}
+
+	claims, _ := state.GetClaims(request.Context())
+	controller.AuthService.Logout(request.Context(), claims, logoutRequest)
+	httphelper.Write(request.Context(), writer, nil)
+}
+
+func bindAndValidate(request *http.Request, params httprouter.Params, result interface{}) error {
+	if err := httphelper.Bind(request, params, result); err != nil {
+		return err
+	}
+	return validators.Validate(result)
+}
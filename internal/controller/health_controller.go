@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/health"
+)
+
+type HealthController interface {
+	Liveness(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Readiness(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+}
+
+type HealthControllerImpl struct {
+	Registry *health.Registry
+}
+
+func NewHealthController(registry *health.Registry) HealthController {
+	return &HealthControllerImpl{
+		Registry: registry,
+	}
+}
+
+// Liveness only tells the process is serving, a failing dependency must not restart the pod.
+func (controller *HealthControllerImpl) Liveness(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	writeHealth(writer, http.StatusOK, health.Report{Status: health.StatusUp})
+}
+
+func (controller *HealthControllerImpl) Readiness(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	report := controller.Registry.Ready(request.Context())
+
+	status := http.StatusOK
+	if report.Status != health.StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+	writeHealth(writer, status, report.Public())
+}
+
+// writeHealth writes the report without api.ApiResponse, probes only read the status and the checks.
+// The probes are unauthenticated, the check errors are logged by the registry instead.
+func writeHealth(writer http.ResponseWriter, status int, report health.Report) {
+	writer.Header().Set(state.HttpHeaders().ContentType.String(), state.HttpContentTypeValues().ApplicationJson)
+	writer.Header().Set(state.HttpHeaders().CacheControl.String(), "no-store")
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(report)
+}
@@ -0,0 +1,13 @@
+package entity
+
+type Role struct {
+	Id          int64
+	Name        string
+	Description string
+}
+
+type Permission struct {
+	Id          int64
+	Name        string
+	Description string
+}
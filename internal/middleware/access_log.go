@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/array"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/httphelper"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// Route stores the registered path of the route in the context, so logs group requests by route instead of URL.
+func Route(pattern string) Middleware {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			ctx := state.WithRoutePattern(request.Context(), pattern)
+			next(writer, request.WithContext(ctx), params)
+		}
+	}
+}
+
+// AccessLog writes one log line per request, at error level for 5xx and warning level for 4xx responses.
+// Routes listed in log.access.skipPaths are not logged.
+func AccessLog() Middleware {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			startTime := time.Now()
+			writer.Header().Set(state.HttpHeaders().StartTime.String(), strconv.FormatInt(startTime.UnixNano(), 10))
+
+			resource := state.RoutePattern(request.Context())
+			if resource == "" {
+				resource = request.URL.Path
+			}
+
+			if array.InArray(resource, config.Get().Log.Access.SkipPaths) {
+				next(writer, request, params)
+				return
+			}
+
+			recorder := newResponseWriter(writer)
+			next(recorder, request, params)
+
+			status := recorder.Status()
+			logger.WithFields(request.Context(), logrus.Fields{
+				logger.LoggerField().RequestMethod: request.Method,
+				logger.LoggerField().Resource:      resource,
+				logger.LoggerField().Path:          request.URL.Path,
+				logger.LoggerField().Status:        status,
+				logger.LoggerField().ResponseSize:  recorder.bytes,
+				logger.LoggerField().LatencyMs:     time.Since(startTime).Milliseconds(),
+				logger.LoggerField().RemoteIp:      httphelper.ClientIp(request),
+				logger.LoggerField().UserAgent:     request.UserAgent(),
+			}).Log(accessLogLevel(status), fmt.Sprintf("%s %s %d", request.Method, resource, status))
+		}
+	}
+}
+
+func accessLogLevel(status int) logrus.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return logrus.ErrorLevel
+	case status >= http.StatusBadRequest:
+		return logrus.WarnLevel
+	default:
+		return logrus.InfoLevel
+	}
+}
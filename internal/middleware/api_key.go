@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	config "github.com/mochammadshenna/arch-pba-template/config"
+)
+
+const headerApiKey = "X-Api-Key"
+
+// apiKeyName returns the name of the auth.apiKeys entry matching the X-Api-Key header, empty when
+// the header is missing or unknown. Callers key on the name, never on the raw header value.
+func apiKeyName(request *http.Request) string {
+	value := request.Header.Get(headerApiKey)
+	if value == "" {
+		return ""
+	}
+
+	for name, apiKey := range config.Get().Auth.ApiKeys {
+		if apiKey.Key != "" && subtle.ConstantTimeCompare([]byte(value), []byte(apiKey.Key)) == 1 {
+			return name
+		}
+	}
+	return ""
+}
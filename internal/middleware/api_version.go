@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+)
+
+const (
+	headerDeprecation = "Deprecation"
+	headerSunset      = "Sunset"
+)
+
+// ApiVersion tells the client which API version served the request, and when the version is
+// deprecated or removed with the Deprecation (RFC 9745) and Sunset (RFC 8594) headers.
+// Zero times are not sent.
+func ApiVersion(version int, deprecatedAt, sunsetAt time.Time) Middleware {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			writer.Header().Set(state.HttpHeaders().ApiVersion.String(), fmt.Sprintf("v%d", version))
+			if !deprecatedAt.IsZero() {
+				writer.Header().Set(headerDeprecation, fmt.Sprintf("@%d", deprecatedAt.Unix()))
+			}
+			if !sunsetAt.IsZero() {
+				writer.Header().Set(headerSunset, sunsetAt.UTC().Format(http.TimeFormat))
+			}
+			next(writer, request, params)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mochammadshenna/arch-pba-template/internal/model/api"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exceptioncode"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/httphelper"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/token"
+)
+
+const headerWwwAuthenticate = "WWW-Authenticate"
+
+// Authenticate verifies the bearer access token and puts its claims in the context.
+func Authenticate(tokenManager *token.Manager, tokenStore token.Store) Middleware {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			ctx := request.Context()
+
+			scheme, accessToken, ok := strings.Cut(request.Header.Get(state.HttpHeaders().Authorization.String()), " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") || accessToken == "" {
+				writeUnauthorized(writer, request, "missing bearer token")
+				return
+			}
+
+			claims, err := tokenManager.ParseAccessToken(accessToken)
+			if err != nil {
+				logger.Debug(ctx, err)
+				writeUnauthorized(writer, request, "invalid or expired token")
+				return
+			}
+
+			revoked, err := tokenStore.IsRevoked(ctx, claims.ID)
+			if err != nil {
+				logger.Errorf(ctx, "failed to check token revocation; err=%+v", err)
+				httphelper.WriteErrorStatus(ctx, writer, http.StatusInternalServerError, api.ErrorResponse{
+					Code:    exceptioncode.CodeInternalServerError,
+					Message: "unable to verify token",
+				})
+				return
+			}
+			if revoked {
+				writeUnauthorized(writer, request, "token has been revoked")
+				return
+			}
+
+			next(writer, request.WithContext(state.WithClaims(ctx, claims)), params)
+		}
+	}
+}
+
+func writeUnauthorized(writer http.ResponseWriter, request *http.Request, message string) {
+	writer.Header().Set(headerWwwAuthenticate, "Bearer")
+	httphelper.WriteErrorStatus(request.Context(), writer, http.StatusUnauthorized, api.ErrorResponse{
+		Code:    exceptioncode.CodeUnauthorized,
+		Message: message,
+	})
+}
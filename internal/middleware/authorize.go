@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mochammadshenna/arch-pba-template/internal/model/api"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/authorization"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exceptioncode"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/httphelper"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
+)
+
+// RequirePermission denies the request with 403 unless the caller has every permission.
+// It must run after Authenticate.
+func RequirePermission(permissions ...authorization.Permission) Middleware {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			ctx := request.Context()
+
+			missing := authorization.MissingPermissions(ctx, permissions...)
+			if len(missing) > 0 {
+				claims, _ := state.GetClaims(ctx)
+				logger.Warnf(ctx, "permission denied; user=%s method=%s route=%s missing=%v",
+					claims.Subject, request.Method, state.RoutePattern(ctx), missing)
+
+				httphelper.WriteErrorStatus(ctx, writer, http.StatusForbidden, api.ErrorResponse{
+					Code:    exceptioncode.CodeForbidden,
+					Message: fmt.Sprintf("missing permission %v", missing),
+				})
+				return
+			}
+
+			next(writer, request, params)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/mochammadshenna/arch-pba-template/internal/model/api"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/array"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exceptioncode"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/httphelper"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/version"
+)
+
+// ClientInfo parses the Platform-Type, Platform and Version headers into state.ClientInfo.
+// Apps older than client.minVersions of their platform get 426 with UPGRADE_REQUIRED.
+func ClientInfo() Middleware {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			ctx := request.Context()
+
+			clientInfo, errs := parseClientInfo(request)
+			if len(errs) > 0 {
+				httphelper.WriteErrorStatus(ctx, writer, http.StatusBadRequest, api.ErrorResponse{
+					Code:    exceptioncode.CodeInvalidRequest,
+					Message: "invalid client headers",
+					Errors:  errs,
+				})
+				return
+			}
+
+			if minVersion, ok := minVersionOf(ctx, clientInfo.Platform); ok {
+				if clientInfo.Version == nil || clientInfo.Version.LessThan(minVersion) {
+					httphelper.WriteErrorStatus(ctx, writer, http.StatusUpgradeRequired, api.ErrorResponse{
+						Code:    exceptioncode.CodeUpgradeRequired,
+						Message: fmt.Sprintf("please update the app to version %s or newer", minVersion),
+						Errors: []api.ErrorValidate{{
+							Key:     state.HttpHeaders().Version.String(),
+							Code:    exceptioncode.CodeUpgradeRequired,
+							Message: fmt.Sprintf("minimum version for %s is %s", clientInfo.Platform, minVersion),
+						}},
+					})
+					return
+				}
+			}
+
+			next(writer, request.WithContext(state.WithClientInfo(ctx, clientInfo)), params)
+		}
+	}
+}
+
+func parseClientInfo(request *http.Request) (state.ClientInfo, []api.ErrorValidate) {
+	clientInfo := state.ClientInfo{}
+	errs := []api.ErrorValidate{}
+
+	header := state.HttpHeaders()
+	if v := strings.ToLower(request.Header.Get(header.PlatformType.String())); v != "" {
+		clientInfo.PlatformType = state.PlatformType(v)
+		if !array.InArray(clientInfo.PlatformType, state.PlatformTypes) {
+			errs = append(errs, invalidHeader(header.PlatformType.String(), fmt.Sprintf("must be one of %v", state.PlatformTypes)))
+		}
+	}
+
+	if v := strings.ToLower(request.Header.Get(header.Platform.String())); v != "" {
+		clientInfo.Platform = state.Platform(v)
+		if !array.InArray(clientInfo.Platform, state.Platforms) {
+			errs = append(errs, invalidHeader(header.Platform.String(), fmt.Sprintf("must be one of %v", state.Platforms)))
+		}
+	}
+
+	if v := request.Header.Get(header.Version.String()); v != "" {
+		parsed, err := version.Parse(v)
+		if err != nil {
+			errs = append(errs, invalidHeader(header.Version.String(), err.Error()))
+		} else {
+			clientInfo.Version = &parsed
+		}
+	}
+
+	return clientInfo, errs
+}
+
+func minVersionOf(ctx context.Context, platform state.Platform) (version.Version, bool) {
+	raw, ok := config.Get().Client.MinVersions[string(platform)]
+	if !ok || raw == "" {
+		return version.Version{}, false
+	}
+
+	minVersion, err := version.Parse(raw)
+	if err != nil {
+		logger.Errorf(ctx, "ignoring invalid client.minVersions.%s; err=%+v", platform, err)
+		return version.Version{}, false
+	}
+	return minVersion, true
+}
+
+func invalidHeader(key, message string) api.ErrorValidate {
+	return api.ErrorValidate{
+		Key:     key,
+		Code:    "VALIDATION",
+		Message: message,
+	}
+}
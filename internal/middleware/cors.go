@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	config "github.com/mochammadshenna/arch-pba-template/config"
+)
+
+const (
+	headerOrigin                        = "Origin"
+	headerVary                          = "Vary"
+	headerAccessControlRequestMethod    = "Access-Control-Request-Method"
+	headerAccessControlRequestHeaders   = "Access-Control-Request-Headers"
+	headerAccessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	headerAccessControlAllowMethods     = "Access-Control-Allow-Methods"
+	headerAccessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	headerAccessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	headerAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	headerAccessControlMaxAge           = "Access-Control-Max-Age"
+)
+
+// Cors adds the CORS response headers for allowed origins. The cors config is read on every request
+// so changes to the YAML file apply without a restart.
+func Cors() Middleware {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			corsConfig := config.Get().Cors
+			origin := request.Header.Get(headerOrigin)
+
+			writer.Header().Add(headerVary, headerOrigin)
+			if allowOrigin, credentials := allowedOrigin(corsConfig, origin); allowOrigin != "" {
+				writer.Header().Set(headerAccessControlAllowOrigin, allowOrigin)
+				if credentials {
+					writer.Header().Set(headerAccessControlAllowCredentials, "true")
+				}
+				if len(corsConfig.ExposedHeaders) > 0 {
+					writer.Header().Set(headerAccessControlExposeHeaders, strings.Join(corsConfig.ExposedHeaders, ", "))
+				}
+			}
+
+			next(writer, request, params)
+		}
+	}
+}
+
+// CorsPreflight answers preflight requests, it is meant for httprouter.Router.GlobalOPTIONS
+// so every registered path gets one.
+func CorsPreflight() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		corsConfig := config.Get().Cors
+		origin := request.Header.Get(headerOrigin)
+
+		writer.Header().Add(headerVary, headerOrigin)
+		writer.Header().Add(headerVary, headerAccessControlRequestMethod)
+		writer.Header().Add(headerVary, headerAccessControlRequestHeaders)
+
+		allowOrigin, credentials := allowedOrigin(corsConfig, origin)
+		if allowOrigin == "" || request.Header.Get(headerAccessControlRequestMethod) == "" {
+			writer.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		writer.Header().Set(headerAccessControlAllowOrigin, allowOrigin)
+		writer.Header().Set(headerAccessControlAllowMethods, strings.Join(corsConfig.AllowedMethods, ", "))
+
+		allowedHeaders := strings.Join(corsConfig.AllowedHeaders, ", ")
+		if allowedHeaders == "*" {
+			allowedHeaders = request.Header.Get(headerAccessControlRequestHeaders)
+		}
+		if allowedHeaders != "" {
+			writer.Header().Set(headerAccessControlAllowHeaders, allowedHeaders)
+		}
+
+		if credentials {
+			writer.Header().Set(headerAccessControlAllowCredentials, "true")
+		}
+		if corsConfig.MaxAge > 0 {
+			writer.Header().Set(headerAccessControlMaxAge, strconv.Itoa(int(corsConfig.MaxAge.Seconds())))
+		}
+
+		writer.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// allowedOrigin returns the Access-Control-Allow-Origin value for origin, empty when it is not allowed,
+// and whether credentials are allowed. An origin only matched by "*" gets a literal "*" without
+// credentials, so "*" never lets any site send cookies or the Authorization header.
+func allowedOrigin(corsConfig config.CorsConfig, origin string) (string, bool) {
+	if origin == "" {
+		return "", false
+	}
+	if isAllowedOrigin(corsConfig.AllowedOrigins, origin) {
+		return origin, corsConfig.AllowCredentials
+	}
+	for _, allowed := range corsConfig.AllowedOrigins {
+		if allowed == "*" {
+			return "*", false
+		}
+	}
+	return "", false
+}
+
+// isAllowedOrigin matches origin against the explicit origins and wildcard subdomain patterns, "*" is not matched.
+func isAllowedOrigin(allowedOrigins []string, origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range allowedOrigins {
+		if matchOrigin(strings.ToLower(allowed), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchOrigin matches an origin against an exact origin or a pattern with one wildcard subdomain.
+func matchOrigin(pattern, origin string) bool {
+	if pattern == "*" {
+		return false
+	}
+	if pattern == origin {
+		return true
+	}
+
+	prefix, suffix, ok := strings.Cut(pattern, "*")
+	if !ok || !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) || len(origin) <= len(prefix)+len(suffix) {
+		return false
+	}
+
+	subdomain := origin[len(prefix) : len(origin)-len(suffix)]
+	return !strings.ContainsAny(subdomain, "/:")
+}
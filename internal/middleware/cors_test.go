@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"testing"
+
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAllowedOrigin(t *testing.T) {
+	allowed := []string{"https://cms.example.com", "https://*.partner.example.com"}
+
+	assert.True(t, isAllowedOrigin(allowed, "https://cms.example.com"))
+	assert.True(t, isAllowedOrigin(allowed, "https://Hotel.Partner.example.com"))
+	assert.False(t, isAllowedOrigin(allowed, "https://partner.example.com"))
+	assert.False(t, isAllowedOrigin(allowed, "http://hotel.partner.example.com"))
+	assert.False(t, isAllowedOrigin(allowed, "https://evil.com/.partner.example.com"))
+	assert.False(t, isAllowedOrigin([]string{"*"}, "https://anything.dev"))
+}
+
+func TestAllowedOriginWithAnyOrigin(t *testing.T) {
+	corsConfig := config.CorsConfig{AllowedOrigins: []string{"https://cms.example.com", "*"}, AllowCredentials: true}
+
+	origin, credentials := allowedOrigin(corsConfig, "https://cms.example.com")
+	assert.Equal(t, "https://cms.example.com", origin)
+	assert.True(t, credentials)
+
+	origin, credentials = allowedOrigin(corsConfig, "https://anything.dev")
+	assert.Equal(t, "*", origin)
+	assert.False(t, credentials)
+
+	origin, _ = allowedOrigin(config.CorsConfig{AllowedOrigins: []string{"https://cms.example.com"}}, "https://anything.dev")
+	assert.Empty(t, origin)
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mochammadshenna/arch-pba-template/internal/model/api"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exceptioncode"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/httphelper"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/idempotency"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
+)
+
+// Idempotency replays the stored response of a POST or PATCH sent again with the same Idempotency-Key.
+// Keys are scoped by caller so a key cannot replay the response of someone else.
+// Responses with a 5xx status are not stored so the client can retry them.
+func Idempotency(store idempotency.Store, ttl time.Duration) Middleware {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			key := request.Header.Get(state.HttpHeaders().IdempotencyKey.String())
+			if key == "" || (request.Method != http.MethodPost && request.Method != http.MethodPatch) {
+				next(writer, request, params)
+				return
+			}
+
+			ctx := request.Context()
+			key = idempotencyScope(request) + ":" + key
+			body, err := io.ReadAll(request.Body)
+			if err != nil {
+				httphelper.WriteErrorStatus(ctx, writer, http.StatusBadRequest, api.ErrorResponse{
+					Code:    exceptioncode.CodeInvalidRequest,
+					Message: err.Error(),
+				})
+				return
+			}
+			request.Body = io.NopCloser(bytes.NewReader(body))
+
+			fingerprint := idempotency.Fingerprint(request.Method, request.URL.Path, body)
+			existing, reserved, err := store.Reserve(ctx, key, idempotency.Record{Fingerprint: fingerprint}, ttl)
+			if err != nil {
+				logger.Errorf(ctx, "idempotency store unavailable, handling request without it; err=%+v", err)
+				next(writer, request, params)
+				return
+			}
+			if !reserved {
+				replay(ctx, writer, existing, fingerprint)
+				return
+			}
+
+			completed := false
+			defer func() {
+				if !completed {
+					if err := store.Release(context.WithoutCancel(ctx), key); err != nil {
+						logger.Errorf(ctx, "failed to release idempotency key; err=%+v", err)
+					}
+				}
+			}()
+
+			recorder := newResponseWriter(writer)
+			recorder.body = &bytes.Buffer{}
+			next(recorder, request, params)
+
+			if recorder.Status() >= http.StatusInternalServerError {
+				return
+			}
+
+			record := idempotency.Record{
+				Fingerprint: fingerprint,
+				Completed:   true,
+				Status:      recorder.Status(),
+				Header:      writer.Header().Clone(),
+				Body:        recorder.body.Bytes(),
+			}
+			if err := store.Complete(context.WithoutCancel(ctx), key, record, ttl); err != nil {
+				logger.Errorf(ctx, "failed to store idempotent response; err=%+v", err)
+				return
+			}
+			completed = true
+		}
+	}
+}
+
+// idempotencyScope is the authenticated user, the API key or the client IP.
+func idempotencyScope(request *http.Request) string {
+	if claims, ok := state.GetClaims(request.Context()); ok && claims.Subject != "" {
+		return "user:" + claims.Subject
+	}
+	if name := apiKeyName(request); name != "" {
+		return "apiKey:" + name
+	}
+	return "ip:" + httphelper.ClientIp(request)
+}
+
+func replay(ctx context.Context, writer http.ResponseWriter, record idempotency.Record, fingerprint string) {
+	if record.Fingerprint != fingerprint {
+		httphelper.WriteErrorStatus(ctx, writer, http.StatusUnprocessableEntity, api.ErrorResponse{
+			Code:    exceptioncode.CodeIdempotencyKeyReused,
+			Message: "Idempotency-Key was already used for a different request",
+		})
+		return
+	}
+
+	if !record.Completed {
+		httphelper.WriteErrorStatus(ctx, writer, http.StatusConflict, api.ErrorResponse{
+			Code:    exceptioncode.CodeRequestInProgress,
+			Message: "a request with this Idempotency-Key is still being processed",
+		})
+		return
+	}
+
+	for name, values := range record.Header {
+		if name == state.HttpHeaders().RequestId.String() {
+			continue
+		}
+		writer.Header()[name] = values
+	}
+	writer.Header().Set(state.HttpHeaders().IdempotentReplayed.String(), "true")
+	writer.WriteHeader(record.Status)
+	_, _ = writer.Write(record.Body)
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/idempotency"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotency(t *testing.T) {
+	calls := 0
+	handle := Chain(func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		calls++
+		writer.WriteHeader(http.StatusCreated)
+		_, _ = writer.Write([]byte(`{"id":1}`))
+	}, Idempotency(idempotency.NewMemoryStore(), time.Hour))
+
+	send := func(body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/api/hotel", strings.NewReader(body))
+		request.Header.Set("Idempotency-Key", "key-1")
+		recorder := httptest.NewRecorder()
+		handle(recorder, request, nil)
+		return recorder
+	}
+
+	first := send(`{"name":"Aston"}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+
+	replayed := send(`{"name":"Aston"}`)
+	assert.Equal(t, http.StatusCreated, replayed.Code)
+	assert.Equal(t, `{"id":1}`, replayed.Body.String())
+	assert.Equal(t, "true", replayed.Header().Get("Idempotent-Replayed"))
+
+	reused := send(`{"name":"Ibis"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotencyIsScopedByCaller(t *testing.T) {
+	calls := 0
+	handle := Chain(func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		calls++
+		writer.WriteHeader(http.StatusCreated)
+	}, Idempotency(idempotency.NewMemoryStore(), time.Hour))
+
+	send := func(subject string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/api/hotel", strings.NewReader(`{}`))
+		request = request.WithContext(state.WithClaims(request.Context(), token.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: subject}}))
+		request.Header.Set("Idempotency-Key", "key-1")
+		recorder := httptest.NewRecorder()
+		handle(recorder, request, nil)
+		return recorder
+	}
+
+	assert.Empty(t, send("1").Header().Get("Idempotent-Replayed"))
+	assert.Empty(t, send("2").Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, "true", send("1").Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 2, calls)
+}
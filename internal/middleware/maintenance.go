@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/mochammadshenna/arch-pba-template/internal/model/api"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exceptioncode"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/httphelper"
+)
+
+const (
+	MaintenanceOff      = "off"
+	MaintenanceReadOnly = "readOnly"
+	MaintenanceFull     = "full"
+
+	defaultMaintenanceRetryAfter = time.Minute
+	defaultMaintenanceMessage    = "service is under maintenance, please try again later"
+)
+
+// Maintenance answers 503 with Retry-After while maintenance.mode is full, or only for mutating
+// methods while it is readOnly. The maintenance config is read on every request so toggling the
+// mode in the YAML file applies without a restart.
+func Maintenance() Middleware {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			ctx := request.Context()
+			maintenance := config.Get().Maintenance
+
+			if !underMaintenance(maintenance, request, state.RoutePattern(ctx)) {
+				next(writer, request, params)
+				return
+			}
+
+			retryAfter := maintenance.RetryAfter
+			if retryAfter <= 0 {
+				retryAfter = defaultMaintenanceRetryAfter
+			}
+			message := maintenance.Message
+			if message == "" {
+				message = defaultMaintenanceMessage
+			}
+
+			writer.Header().Set(headerRetryAfter, strconv.Itoa(int(retryAfter.Seconds())))
+			httphelper.WriteErrorStatus(ctx, writer, http.StatusServiceUnavailable, api.ErrorResponse{
+				Code:    exceptioncode.CodeMaintenance,
+				Message: message,
+			})
+		}
+	}
+}
+
+func underMaintenance(maintenance config.MaintenanceConfig, request *http.Request, pattern string) bool {
+	// an unknown mode fails closed as full, the config validation rejects it on reload anyway
+	switch maintenance.Mode {
+	case MaintenanceOff, "":
+		return false
+	case MaintenanceReadOnly:
+		if !isMutating(request.Method) {
+			return false
+		}
+	}
+
+	if pattern == "" {
+		pattern = request.URL.Path
+	}
+	for _, route := range maintenance.ExemptRoutes {
+		if route == pattern || strings.HasSuffix(route, "*") && strings.HasPrefix(pattern, strings.TrimSuffix(route, "*")) {
+			return false
+		}
+	}
+
+	return !isAllowedIp(maintenance.AllowedIps, httphelper.ClientIp(request))
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+func isAllowedIp(allowed []string, clientIp string) bool {
+	ip := net.ParseIP(clientIp)
+	if ip == nil {
+		return false
+	}
+
+	for _, value := range allowed {
+		if strings.Contains(value, "/") {
+			if _, network, err := net.ParseCIDR(value); err == nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if allowedIp := net.ParseIP(value); allowedIp != nil && allowedIp.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
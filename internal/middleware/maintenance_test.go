@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnderMaintenance(t *testing.T) {
+	request := func(method, clientIp string) *http.Request {
+		r := httptest.NewRequest(method, "/api/v1/brand", nil)
+		r.RemoteAddr = clientIp + ":1234"
+		return r
+	}
+
+	readOnly := config.MaintenanceConfig{
+		Mode:         MaintenanceReadOnly,
+		AllowedIps:   []string{"10.0.0.0/8", "203.0.113.7"},
+		ExemptRoutes: []string{"/api/auth/*"},
+	}
+	assert.False(t, underMaintenance(readOnly, request(http.MethodGet, "198.51.100.1"), "/api/v1/brand"))
+	assert.True(t, underMaintenance(readOnly, request(http.MethodPost, "198.51.100.1"), "/api/v1/brand"))
+	assert.False(t, underMaintenance(readOnly, request(http.MethodPost, "198.51.100.1"), "/api/auth/login"))
+	assert.False(t, underMaintenance(readOnly, request(http.MethodPost, "10.1.2.3"), "/api/v1/brand"))
+	assert.False(t, underMaintenance(readOnly, request(http.MethodPost, "203.0.113.7"), "/api/v1/brand"))
+
+	// an allowed IP sent by the client itself is not trusted
+	spoofed := request(http.MethodPost, "198.51.100.1")
+	spoofed.Header.Set("X-Forwarded-For", "203.0.113.7")
+	assert.True(t, underMaintenance(readOnly, spoofed, "/api/v1/brand"))
+
+	full := config.MaintenanceConfig{Mode: MaintenanceFull}
+	assert.True(t, underMaintenance(full, request(http.MethodGet, "198.51.100.1"), "/api/v1/brand"))
+	assert.False(t, underMaintenance(config.MaintenanceConfig{Mode: MaintenanceOff}, request(http.MethodPost, "198.51.100.1"), ""))
+	assert.False(t, underMaintenance(config.MaintenanceConfig{}, request(http.MethodPost, "198.51.100.1"), ""))
+	assert.True(t, underMaintenance(config.MaintenanceConfig{Mode: "ful"}, request(http.MethodGet, "198.51.100.1"), "/api/v1/brand"))
+}
@@ -0,0 +1,14 @@
+package middleware
+
+import "github.com/julienschmidt/httprouter"
+
+// Middleware wraps a handle to run code before and after it.
+type Middleware func(next httprouter.Handle) httprouter.Handle
+
+// Chain wraps handle with the middlewares, the first middleware being the outermost one.
+func Chain(handle httprouter.Handle, middlewares ...Middleware) httprouter.Handle {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handle = middlewares[i](handle)
+	}
+	return handle
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/httphelper"
+)
+
+// ProblemDetails renders every error of the wrapped routes as application/problem+json.
+func ProblemDetails() Middleware {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			ctx := httphelper.WithProblemDetails(request.Context())
+			next(writer, request.WithContext(ctx), params)
+		}
+	}
+}
+
+// NegotiateErrorFormat renders errors as application/problem+json when the client accepts it.
+func NegotiateErrorFormat() Middleware {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			if httphelper.AcceptsProblemDetails(request) {
+				request = request.WithContext(httphelper.WithProblemDetails(request.Context()))
+			}
+			next(writer, request, params)
+		}
+	}
+}
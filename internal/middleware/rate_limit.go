@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/mochammadshenna/arch-pba-template/internal/model/api"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exceptioncode"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/httphelper"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/ratelimit"
+)
+
+const (
+	headerRateLimitLimit     = "RateLimit-Limit"
+	headerRateLimitRemaining = "RateLimit-Remaining"
+	headerRateLimitReset     = "RateLimit-Reset"
+	headerRetryAfter         = "Retry-After"
+)
+
+// RateLimitKey identifies who is limited, an empty key falls back to the client IP.
+type RateLimitKey func(request *http.Request) string
+
+// rateLimitKeys are the keys selectable with rateLimit.groups.<group>.key
+var rateLimitKeys = map[string]RateLimitKey{
+	"ip":     httphelper.ClientIp,
+	"apiKey": apiKeyName,
+	"user": func(request *http.Request) string {
+		claims, _ := state.GetClaims(request.Context())
+		return claims.Subject
+	},
+}
+
+// RateLimit limits the requests of a route group with the rule of rateLimit.groups.<group>.
+// The rule is read on every request so limits can be changed without a restart.
+func RateLimit(store ratelimit.Store, group string) Middleware {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			ctx := request.Context()
+			rule, ok := config.Get().RateLimit.Groups[group]
+			if !ok || rule.Limit <= 0 || rule.Window <= 0 {
+				next(writer, request, params)
+				return
+			}
+
+			keyType, key := rule.Key, ""
+			if keyFunc, ok := rateLimitKeys[keyType]; ok {
+				key = keyFunc(request)
+			}
+			if key == "" {
+				keyType, key = "ip", httphelper.ClientIp(request)
+			}
+
+			result, err := store.Allow(ctx, fmt.Sprintf("%s:%s:%s", group, keyType, key), ratelimit.Rule{
+				Limit:  rule.Limit,
+				Window: rule.Window,
+			})
+			if err != nil {
+				logger.Errorf(ctx, "rate limit store unavailable, request is not limited; err=%+v", err)
+				next(writer, request, params)
+				return
+			}
+
+			writer.Header().Set(headerRateLimitLimit, strconv.Itoa(result.Limit))
+			writer.Header().Set(headerRateLimitRemaining, strconv.Itoa(result.Remaining))
+			writer.Header().Set(headerRateLimitReset, strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+			if !result.Allowed {
+				writer.Header().Set(headerRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				httphelper.WriteErrorStatus(ctx, writer, http.StatusTooManyRequests, api.ErrorResponse{
+					Code:    exceptioncode.CodeTooManyRequests,
+					Message: "too many requests, please retry later",
+				})
+				return
+			}
+
+			next(writer, request, params)
+		}
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
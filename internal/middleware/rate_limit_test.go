@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitByApiKey(t *testing.T) {
+	config.Set(config.Config{
+		Auth: config.AuthConfig{ApiKeys: map[string]config.ApiKeyConfig{"acme": {Key: "acme-key-0123456789"}}},
+		RateLimit: config.RateLimitConfig{Groups: map[string]config.RateLimitRule{
+			"public": {Limit: 1, Window: time.Minute, Key: "apiKey"},
+		}},
+	})
+	defer config.Set(config.Config{})
+
+	handle := Chain(func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		writer.WriteHeader(http.StatusOK)
+	}, RateLimit(ratelimit.NewMemoryStore(), "public"))
+
+	send := func(apiKey string) int {
+		request := httptest.NewRequest(http.MethodGet, "/api/hotel", nil)
+		request.Header.Set("X-Api-Key", apiKey)
+		recorder := httptest.NewRecorder()
+		handle(recorder, request, nil)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send("acme-key-0123456789"))
+	assert.Equal(t, http.StatusTooManyRequests, send("acme-key-0123456789"))
+	// unknown keys share the bucket of the client IP instead of getting a fresh one each
+	assert.Equal(t, http.StatusOK, send("random-1"))
+	assert.Equal(t, http.StatusTooManyRequests, send("random-2"))
+}
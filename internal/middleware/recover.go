@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exception"
+)
+
+// Recover hands panics to exception.ErrorHandler with the request as seen by the handler.
+// Unlike the router PanicHandler, it keeps the context values set by the outer middlewares.
+func Recover() Middleware {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			defer func() {
+				if err := recover(); err != nil {
+					exception.ErrorHandler(writer, request, err)
+				}
+			}()
+			next(writer, request, params)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+)
+
+const maxRequestIdLength = 128
+
+// RequestId keeps the incoming Request-Id, or generates one, and puts it in the context and the response header.
+func RequestId() Middleware {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			requestId := request.Header.Get(state.HttpHeaders().RequestId.String())
+			if !isValidRequestId(requestId) {
+				requestId = uuid.New().String()
+			}
+
+			writer.Header().Set(state.HttpHeaders().RequestId.String(), requestId)
+			ctx := state.WithRequestId(request.Context(), requestId)
+			next(writer, request.WithContext(ctx), params)
+		}
+	}
+}
+
+func isValidRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIdLength {
+		return false
+	}
+	for _, c := range requestId {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
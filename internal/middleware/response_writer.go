@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// responseWriter records the status and size of what the wrapped handler writes,
+// and keeps a copy of the body when body is set.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+	body   *bytes.Buffer
+}
+
+func newResponseWriter(writer http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: writer}
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	if w.body != nil {
+		w.body.Write(b[:n])
+	}
+	return n, err
+}
+
+func (w *responseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/mochammadshenna/arch-pba-template/internal/model/api"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exception"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exceptioncode"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/httphelper"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
+)
+
+const (
+	// NoTimeout disables the request deadline of a route, for long-running routes like exports.
+	NoTimeout time.Duration = -1
+
+	// writeDeadlineMargin is the time left to write the response after a route timeout that
+	// extends the server write deadline.
+	writeDeadlineMargin = 5 * time.Second
+)
+
+// RouteTimeout overrides server.requestTimeout for a route, it has to run before Timeout.
+func RouteTimeout(timeout time.Duration) Middleware {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			ctx := state.WithRouteTimeout(request.Context(), timeout)
+			next(writer, request.WithContext(ctx), params)
+		}
+	}
+}
+
+// Timeout puts a deadline on the request context and answers 503 when the handler is not done in time.
+// The handler writes to a buffer, so its writes after the deadline are dropped instead of mixed into the response.
+// NoTimeout and route timeouts past server.writeTimeout move the write deadline of the server along.
+func Timeout() Middleware {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			serverConfig := config.Get().Server
+			timeout, ok := state.RouteTimeout(request.Context())
+			if ok {
+				timeout = extendWriteDeadline(writer, request, timeout, serverConfig)
+			} else {
+				timeout = serverConfig.RequestTimeout
+			}
+			if timeout <= 0 {
+				next(writer, request, params)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(request.Context(), timeout)
+			defer cancel()
+
+			// the handler and httphelper read Request-Id and Start-Time set by the outer middlewares
+			tw := &timeoutWriter{header: writer.Header().Clone()}
+			done := make(chan struct{})
+			panicChan := make(chan exception.Panic, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicChan <- exception.Panic{Value: p, Stack: debug.Stack()}
+					}
+				}()
+				next(tw, request.WithContext(ctx), params)
+				close(done)
+			}()
+
+			select {
+			case p := <-panicChan:
+				panic(p)
+			case <-done:
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				for name := range writer.Header() {
+					if _, ok := tw.header[name]; !ok {
+						writer.Header().Del(name)
+					}
+				}
+				for name, values := range tw.header {
+					writer.Header()[name] = values
+				}
+				writer.WriteHeader(tw.status())
+				_, _ = writer.Write(tw.body.Bytes())
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.timedOut = true
+				logger.Warnf(ctx, "request timed out after %s; route=%s", timeout, state.RoutePattern(ctx))
+				httphelper.WriteErrorStatus(request.Context(), writer, http.StatusServiceUnavailable, api.ErrorResponse{
+					Code:    exceptioncode.CodeRequestTimeout,
+					Message: "request timed out",
+				})
+			}
+		}
+	}
+}
+
+// extendWriteDeadline moves the write deadline of the server past the route timeout, or clears it
+// for NoTimeout, so the server does not cut the response at server.writeTimeout. When the writer
+// does not support it, the route falls back to server.requestTimeout, which the config keeps
+// below server.writeTimeout.
+func extendWriteDeadline(writer http.ResponseWriter, request *http.Request, timeout time.Duration, serverConfig config.ServerConfig) time.Duration {
+	if serverConfig.WriteTimeout <= 0 || (timeout > 0 && timeout+writeDeadlineMargin <= serverConfig.WriteTimeout) {
+		return timeout
+	}
+
+	deadline := time.Time{}
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout + writeDeadlineMargin)
+	}
+	if err := http.NewResponseController(writer).SetWriteDeadline(deadline); err != nil {
+		logger.Errorf(request.Context(), "cannot extend the write deadline, using server.requestTimeout %s instead of %s; route=%s err=%v",
+			serverConfig.RequestTimeout, timeout, state.RoutePattern(request.Context()), err)
+		return serverConfig.RequestTimeout
+	}
+	return timeout
+}
+
+// timeoutWriter buffers the response until the handler is done, writes after the deadline fail.
+type timeoutWriter struct {
+	mu          sync.Mutex
+	header      http.Header
+	body        bytes.Buffer
+	code        int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+	return tw.body.Write(b)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.writeHeaderLocked(code)
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(code int) {
+	tw.wroteHeader = true
+	tw.code = code
+}
+
+func (tw *timeoutWriter) status() int {
+	if tw.code == 0 {
+		return http.StatusOK
+	}
+	return tw.code
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exception"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	slow := func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		select {
+		case <-request.Context().Done():
+		case <-time.After(100 * time.Millisecond):
+		}
+		time.Sleep(10 * time.Millisecond)
+		_, _ = writer.Write([]byte("late"))
+	}
+
+	recorder := httptest.NewRecorder()
+	Chain(slow, RouteTimeout(20*time.Millisecond), Timeout())(recorder, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "late")
+
+	recorder = httptest.NewRecorder()
+	Chain(slow, RouteTimeout(NoTimeout), Timeout())(recorder, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "late", recorder.Body.String())
+}
+
+func TestTimeoutKeepsOuterHeaders(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	recorder.Header().Set("Request-Id", "req-1")
+
+	Chain(func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		assert.Equal(t, "req-1", writer.Header().Get("Request-Id"))
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusCreated)
+	}, RouteTimeout(time.Second), Timeout())(recorder, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "req-1", recorder.Header().Get("Request-Id"))
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+}
+
+func TestTimeoutPanicKeepsHandlerStack(t *testing.T) {
+	defer func() {
+		p, ok := recover().(exception.Panic)
+		assert.True(t, ok)
+		assert.Equal(t, "boom", p.Value)
+		assert.Contains(t, string(p.Stack), "panickingHandler")
+	}()
+
+	Chain(panickingHandler, RouteTimeout(time.Second), Timeout())(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), nil)
+}
+
+func panickingHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	panic("boom")
+}
+
+// deadlineRecorder supports http.ResponseController.SetWriteDeadline like the writer of the server.
+type deadlineRecorder struct {
+	*httptest.ResponseRecorder
+	deadlines []time.Time
+}
+
+func (r *deadlineRecorder) SetWriteDeadline(deadline time.Time) error {
+	r.deadlines = append(r.deadlines, deadline)
+	return nil
+}
+
+func TestTimeoutExtendsWriteDeadline(t *testing.T) {
+	config.Set(config.Config{Server: config.ServerConfig{RequestTimeout: 30 * time.Second, WriteTimeout: 35 * time.Second}})
+	defer config.Set(config.Config{})
+
+	var deadline time.Time
+	handle := func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		deadline, _ = request.Context().Deadline()
+	}
+	serve := func(writer http.ResponseWriter, timeout time.Duration) {
+		Chain(handle, RouteTimeout(timeout), Timeout())(writer, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+	}
+
+	recorder := &deadlineRecorder{ResponseRecorder: httptest.NewRecorder()}
+	serve(recorder, 10*time.Second)
+	assert.Empty(t, recorder.deadlines)
+
+	serve(recorder, NoTimeout)
+	assert.Equal(t, []time.Time{{}}, recorder.deadlines)
+
+	serve(recorder, 2*time.Minute)
+	assert.Len(t, recorder.deadlines, 2)
+	assert.WithinDuration(t, time.Now().Add(2*time.Minute+writeDeadlineMargin), recorder.deadlines[1], time.Second)
+	assert.WithinDuration(t, time.Now().Add(2*time.Minute), deadline, time.Second)
+
+	// without write deadline support the route is cut at server.requestTimeout before the server cuts it
+	serve(httptest.NewRecorder(), 2*time.Minute)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), deadline, time.Second)
+}
@@ -0,0 +1,34 @@
+package api
+
+type LoginRequest struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// String keeps the password out of the request log.
+func (r LoginRequest) String() string {
+	return "{Username:" + r.Username + " Password:[REDACTED]}"
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+func (r RefreshTokenRequest) String() string {
+	return "{RefreshToken:[REDACTED]}"
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+func (r LogoutRequest) String() string {
+	return "{RefreshToken:[REDACTED]}"
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int64  `json:"expiresIn"`
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const problemTypePrefix = "urn:arch-pba:problem:"
+
+// ProblemDetails is the RFC 7807 representation of an ErrorResponse.
+// Code and Errors are extension members so the conversion is lossless.
+type ProblemDetails struct {
+	Type     string      `json:"type"`
+	Title    string      `json:"title"`
+	Status   int         `json:"status"`
+	Detail   string      `json:"detail,omitempty"`
+	Instance string      `json:"instance,omitempty"`
+	Code     string      `json:"code"`
+	Errors   interface{} `json:"errors,omitempty"`
+}
+
+func NewProblemDetails(status int, err ErrorResponse, instance string) ProblemDetails {
+	problem := ProblemDetails{
+		Type:     ProblemType(err.Code),
+		Title:    http.StatusText(status),
+		Status:   status,
+		Instance: instance,
+		Code:     err.Code,
+		Errors:   err.Errors,
+	}
+	if err.Message != nil {
+		problem.Detail = fmt.Sprint(err.Message)
+	}
+	return problem
+}
+
+// ProblemType builds the problem type URI of an error code, e.g. DATA_NOT_FOUND becomes
+// urn:arch-pba:problem:data-not-found
+func ProblemType(code string) string {
+	if code == "" {
+		return "about:blank"
+	}
+	return problemTypePrefix + strings.ToLower(strings.ReplaceAll(code, "_", "-"))
+}
+
+func (p ProblemDetails) ErrorResponse() ErrorResponse {
+	return ErrorResponse{
+		Code:    p.Code,
+		Message: p.Detail,
+		Errors:  p.Errors,
+	}
+}
@@ -0,0 +1,14 @@
+package api
+
+import "time"
+
+// TimeRange is a closed [From, To] interval bound from a single
+// "from,to" request value, e.g. ?date=2023-10-01,2023-10-31
+type TimeRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+func (t TimeRange) Contains(v time.Time) bool {
+	return !v.Before(t.From) && !v.After(t.To)
+}
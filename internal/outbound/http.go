@@ -0,0 +1,29 @@
+package outbound
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+)
+
+// NewHttpClient returns a client for third party calls that forwards the request ID of the call context.
+func NewHttpClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: requestIdTransport{base: http.DefaultTransport},
+	}
+}
+
+type requestIdTransport struct {
+	base http.RoundTripper
+}
+
+func (t requestIdTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	requestId := state.RequestId(request.Context())
+	if requestId != "" && request.Header.Get(state.HttpHeaders().RequestId.String()) == "" {
+		request = request.Clone(request.Context())
+		request.Header.Set(state.HttpHeaders().RequestId.String(), requestId)
+	}
+	return t.base.RoundTrip(request)
+}
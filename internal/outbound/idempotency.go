@@ -0,0 +1,70 @@
+package outbound
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/mochammadshenna/arch-pba-template/internal/util/idempotency"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	idempotencyKeyPrefix = "idempotency:"
+	reserveAttempts      = 3
+)
+
+// IdempotencyStore shares idempotency records between replicas through Redis.
+type IdempotencyStore struct {
+	client *redis.Client
+}
+
+func NewIdempotencyStore(client *redis.Client) *IdempotencyStore {
+	return &IdempotencyStore{client: client}
+}
+
+func (s *IdempotencyStore) Reserve(ctx context.Context, key string, record idempotency.Record, ttl time.Duration) (idempotency.Record, bool, error) {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return idempotency.Record{}, false, err
+	}
+
+	for attempt := 0; attempt < reserveAttempts; attempt++ {
+		ok, err := s.client.SetNX(ctx, idempotencyKeyPrefix+key, value, ttl).Result()
+		if err != nil {
+			return idempotency.Record{}, false, err
+		}
+		if ok {
+			return record, true, nil
+		}
+
+		stored, err := s.client.Get(ctx, idempotencyKeyPrefix+key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			// released or expired between SetNX and Get, try to take it again
+			continue
+		}
+		if err != nil {
+			return idempotency.Record{}, false, err
+		}
+
+		var existing idempotency.Record
+		err = json.Unmarshal(stored, &existing)
+		return existing, false, err
+	}
+
+	// the key keeps changing hands, answer as if the other request is still in progress
+	return idempotency.Record{Fingerprint: record.Fingerprint}, false, nil
+}
+
+func (s *IdempotencyStore) Complete(ctx context.Context, key string, record idempotency.Record, ttl time.Duration) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, idempotencyKeyPrefix+key, value, ttl).Err()
+}
+
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, idempotencyKeyPrefix+key).Err()
+}
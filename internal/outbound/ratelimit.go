@@ -0,0 +1,63 @@
+package outbound
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/mochammadshenna/arch-pba-template/internal/util/ratelimit"
+	"github.com/redis/go-redis/v9"
+)
+
+const rateLimitKeyPrefix = "ratelimit:"
+
+// tokenBucketScript refills and takes a token atomically, using the Redis clock so replicas agree.
+var tokenBucketScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window_ms = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now_ms = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil then
+	tokens = limit
+	ts = now_ms
+end
+
+tokens = math.min(limit, tokens + (now_ms - ts) * limit / window_ms)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now_ms)
+redis.call('PEXPIRE', KEYS[1], window_ms)
+return {allowed, tostring(tokens)}
+`)
+
+// RateLimitStore shares the token buckets between replicas through Redis.
+type RateLimitStore struct {
+	client *redis.Client
+}
+
+func NewRateLimitStore(client *redis.Client) *RateLimitStore {
+	return &RateLimitStore{client: client}
+}
+
+func (s *RateLimitStore) Allow(ctx context.Context, key string, rule ratelimit.Rule) (ratelimit.Result, error) {
+	reply, err := tokenBucketScript.Run(ctx, s.client, []string{rateLimitKeyPrefix + key}, rule.Limit, rule.Window.Milliseconds()).Slice()
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+
+	allowed, _ := reply[0].(int64)
+	remaining, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(remaining, 64)
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+
+	return ratelimit.NewResult(rule, allowed == 1, tokens), nil
+}
@@ -1 +1,43 @@
 package outbound
+
+import (
+	"context"
+	"fmt"
+
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/health"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+func NewRedis() *redis.Client {
+	client, err := OpenRedis()
+	if err != nil {
+		logger.Fatal(context.TODO(), err)
+	}
+	return client
+}
+
+// OpenRedis connects to the configured redis and returns the error instead of exiting.
+func OpenRedis() (*redis.Client, error) {
+	var redisConfig = config.Get().Redis
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%s", redisConfig.Host, redisConfig.Port),
+		Password: redisConfig.Password,
+		DB:       redisConfig.Db,
+	})
+
+	if err := client.Ping(context.TODO()).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("ping redis %s: %w", client.Options().Addr, err)
+	}
+
+	return client, nil
+}
+
+func RedisCheck(client *redis.Client) health.Check {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
@@ -0,0 +1,62 @@
+package outbound
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/mochammadshenna/arch-pba-template/internal/util/token"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	refreshTokenKeyPrefix = "auth:refresh:"
+	revokedTokenKeyPrefix = "auth:revoked:"
+)
+
+// TokenStore shares refresh tokens and revoked access tokens between replicas through Redis.
+type TokenStore struct {
+	client *redis.Client
+}
+
+func NewTokenStore(client *redis.Client) *TokenStore {
+	return &TokenStore{client: client}
+}
+
+func (s *TokenStore) SaveRefreshToken(ctx context.Context, hash string, refreshToken token.RefreshToken) error {
+	value, err := json.Marshal(refreshToken)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, refreshTokenKeyPrefix+hash, value, time.Until(refreshToken.ExpiresAt)).Err()
+}
+
+func (s *TokenStore) ConsumeRefreshToken(ctx context.Context, hash string) (token.RefreshToken, bool, error) {
+	value, err := s.client.GetDel(ctx, refreshTokenKeyPrefix+hash).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return token.RefreshToken{}, false, nil
+	}
+	if err != nil {
+		return token.RefreshToken{}, false, err
+	}
+
+	var refreshToken token.RefreshToken
+	if err := json.Unmarshal(value, &refreshToken); err != nil {
+		return token.RefreshToken{}, false, err
+	}
+	return refreshToken, true, nil
+}
+
+func (s *TokenStore) Revoke(ctx context.Context, tokenId string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(ctx, revokedTokenKeyPrefix+tokenId, 1, ttl).Err()
+}
+
+func (s *TokenStore) IsRevoked(ctx context.Context, tokenId string) (bool, error) {
+	n, err := s.client.Exists(ctx, revokedTokenKeyPrefix+tokenId).Result()
+	return n > 0, err
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+type RoleRepository interface {
+	FindRoleNamesByUserId(ctx context.Context, tx *sql.Tx, userId int64) ([]string, error)
+	FindPermissionNamesByUserId(ctx context.Context, tx *sql.Tx, userId int64) ([]string, error)
+}
+
+type RoleRepositoryImpl struct{}
+
+func NewRoleRepository() RoleRepository {
+	return &RoleRepositoryImpl{}
+}
+
+func (repository *RoleRepositoryImpl) FindRoleNamesByUserId(ctx context.Context, tx *sql.Tx, userId int64) ([]string, error) {
+	query := `SELECT r.name FROM roles r
+		JOIN user_roles ur ON ur.role_id = r.id
+		WHERE ur.user_id = ?
+		ORDER BY r.name`
+	return queryNames(ctx, tx, query, userId)
+}
+
+func (repository *RoleRepositoryImpl) FindPermissionNamesByUserId(ctx context.Context, tx *sql.Tx, userId int64) ([]string, error) {
+	query := `SELECT DISTINCT p.name FROM permissions p
+		JOIN role_permissions rp ON rp.permission_id = p.id
+		JOIN user_roles ur ON ur.role_id = rp.role_id
+		WHERE ur.user_id = ?
+		ORDER BY p.name`
+	return queryNames(ctx, tx, query, userId)
+}
+
+func queryNames(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mochammadshenna/arch-pba-template/internal/entity"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exceptioncode"
+)
+
+type UserRepository interface {
+	FindByUsername(ctx context.Context, tx *sql.Tx, username string) (entity.User, error)
+	FindById(ctx context.Context, tx *sql.Tx, userId int64) (entity.User, error)
+}
+
+type UserRepositoryImpl struct{}
+
+func NewUserRepository() UserRepository {
+	return &UserRepositoryImpl{}
+}
+
+func (repository *UserRepositoryImpl) FindByUsername(ctx context.Context, tx *sql.Tx, username string) (entity.User, error) {
+	query := "SELECT id, username, password, created_at, updated_at FROM users WHERE username = ?"
+	return scanUser(tx.QueryRowContext(ctx, query, username))
+}
+
+func (repository *UserRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx, userId int64) (entity.User, error) {
+	query := "SELECT id, username, password, created_at, updated_at FROM users WHERE id = ?"
+	return scanUser(tx.QueryRowContext(ctx, query, userId))
+}
+
+func scanUser(row *sql.Row) (entity.User, error) {
+	user := entity.User{}
+	err := row.Scan(&user.Id, &user.Username, &user.Password, &user.CreatedAt, &user.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return user, exceptioncode.ErrEmptyResult
+	}
+	return user, err
+}
@@ -0,0 +1,174 @@
+package router
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mochammadshenna/arch-pba-template/internal/middleware"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/authorization"
+)
+
+// Router registers routes on httprouter through named groups, each with a path prefix and a middleware chain.
+type Router struct {
+	*httprouter.Router
+	routes    []Route
+	versioned map[routeKey]*versionedHandles
+}
+
+// Route is the metadata of a registered route.
+type Route struct {
+	Method      string                     `json:"method"`
+	Path        string                     `json:"path"`
+	Name        string                     `json:"name"`
+	Group       string                     `json:"group"`
+	Version     int                        `json:"version,omitempty"`
+	Permissions []authorization.Permission `json:"permissions"`
+	Timeout     time.Duration              `json:"timeout"` // 0 uses server.requestTimeout, middleware.NoTimeout disables it
+
+	middlewares []middleware.Middleware
+}
+
+type RouteOption func(*Route)
+
+func Name(name string) RouteOption {
+	return func(r *Route) {
+		r.Name = name
+	}
+}
+
+// Permissions denies callers without every permission, the group has to authenticate.
+func Permissions(permissions ...authorization.Permission) RouteOption {
+	return func(r *Route) {
+		r.Permissions = append(r.Permissions, permissions...)
+	}
+}
+
+func Timeout(timeout time.Duration) RouteOption {
+	return func(r *Route) {
+		r.Timeout = timeout
+	}
+}
+
+// Use adds middlewares that only run for this route, after the group ones.
+func Use(middlewares ...middleware.Middleware) RouteOption {
+	return func(r *Route) {
+		r.middlewares = append(r.middlewares, middlewares...)
+	}
+}
+
+func New() *Router {
+	return &Router{Router: httprouter.New()}
+}
+
+// Group creates a top level group.
+func (r *Router) Group(name, prefix string, middlewares ...middleware.Middleware) *Group {
+	return &Group{
+		router:      r,
+		name:        name,
+		prefix:      prefix,
+		middlewares: middlewares,
+	}
+}
+
+// Routes lists the registered routes ordered by path and method.
+func (r *Router) Routes() []Route {
+	routes := make([]Route, len(r.routes))
+	copy(routes, r.routes)
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+type Group struct {
+	router      *Router
+	name        string
+	prefix      string
+	middlewares []middleware.Middleware
+
+	// set for groups below Group.Version, unversionedPrefix is the prefix without /v<version> and
+	// unversionedMiddlewares the chain without the ApiVersion middleware
+	version                int
+	unversionedPrefix      string
+	unversionedMiddlewares []middleware.Middleware
+}
+
+// Group creates a sub group, its middlewares run after the ones of g.
+func (g *Group) Group(name, prefix string, middlewares ...middleware.Middleware) *Group {
+	return &Group{
+		router:                 g.router,
+		name:                   name,
+		prefix:                 g.prefix + prefix,
+		middlewares:            with(g.middlewares, middlewares...),
+		version:                g.version,
+		unversionedPrefix:      g.unversionedPrefix + prefix,
+		unversionedMiddlewares: with(g.unversionedMiddlewares, middlewares...),
+	}
+}
+
+// Use adds middlewares to the routes registered on g afterwards.
+func (g *Group) Use(middlewares ...middleware.Middleware) {
+	g.middlewares = append(g.middlewares, middlewares...)
+}
+
+func (g *Group) GET(path string, handle httprouter.Handle, options ...RouteOption) {
+	g.Handle(http.MethodGet, path, handle, options...)
+}
+
+func (g *Group) POST(path string, handle httprouter.Handle, options ...RouteOption) {
+	g.Handle(http.MethodPost, path, handle, options...)
+}
+
+func (g *Group) PUT(path string, handle httprouter.Handle, options ...RouteOption) {
+	g.Handle(http.MethodPut, path, handle, options...)
+}
+
+func (g *Group) PATCH(path string, handle httprouter.Handle, options ...RouteOption) {
+	g.Handle(http.MethodPatch, path, handle, options...)
+}
+
+func (g *Group) DELETE(path string, handle httprouter.Handle, options ...RouteOption) {
+	g.Handle(http.MethodDelete, path, handle, options...)
+}
+
+// Handle registers the route with, in order: its metadata in the context, the group middlewares,
+// the route middlewares and the permission check.
+func (g *Group) Handle(method, path string, handle httprouter.Handle, options ...RouteOption) {
+	route := Route{
+		Method:  method,
+		Path:    g.prefix + path,
+		Group:   g.name,
+		Version: g.version,
+	}
+	for _, option := range options {
+		option(&route)
+	}
+
+	middlewares := []middleware.Middleware{middleware.Route(route.Path)}
+	if route.Timeout != 0 {
+		middlewares = append(middlewares, middleware.RouteTimeout(route.Timeout))
+	}
+	middlewares = append(middlewares, g.middlewares...)
+	middlewares = append(middlewares, route.middlewares...)
+	if len(route.Permissions) > 0 {
+		middlewares = append(middlewares, middleware.RequirePermission(route.Permissions...))
+	}
+
+	chained := middleware.Chain(handle, middlewares...)
+	g.router.Router.Handle(method, route.Path, chained)
+	if g.version > 0 {
+		g.router.handleVersioned(method, g.unversionedPrefix+path, g.version, chained, g.unversionedMiddlewares)
+	}
+	g.router.routes = append(g.router.routes, route)
+}
+
+func with(base []middleware.Middleware, middlewares ...middleware.Middleware) []middleware.Middleware {
+	result := make([]middleware.Middleware, 0, len(base)+len(middlewares))
+	result = append(result, base...)
+	return append(result, middlewares...)
+}
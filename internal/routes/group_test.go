@@ -0,0 +1,127 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/mochammadshenna/arch-pba-template/internal/middleware"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func trace(calls *[]string, name string) middleware.Middleware {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			*calls = append(*calls, name)
+			next(writer, request, params)
+		}
+	}
+}
+
+func TestGroup(t *testing.T) {
+	calls := []string{}
+	router := New()
+	v1 := router.Group("v1", "/api/v1", trace(&calls, "v1"))
+	hotel := v1.Group("hotel", "/hotel", trace(&calls, "hotel"))
+
+	var pattern string
+	hotel.GET("/:id", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		pattern = state.RoutePattern(request.Context())
+		calls = append(calls, "handle:"+params.ByName("id"))
+	}, Name("hotel.detail"), Use(trace(&calls, "route")))
+	v1.POST("/brand", func(http.ResponseWriter, *http.Request, httprouter.Params) {}, Name("brand.create"))
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/hotel/7", nil))
+
+	assert.Equal(t, []string{"v1", "hotel", "route", "handle:7"}, calls)
+	assert.Equal(t, "/api/v1/hotel/:id", pattern)
+
+	routes := router.Routes()
+	assert.Len(t, routes, 2)
+	assert.Equal(t, Route{Method: http.MethodPost, Path: "/api/v1/brand", Name: "brand.create", Group: "v1"}, routes[0])
+	assert.Equal(t, "hotel.detail", routes[1].Name)
+	assert.Equal(t, "hotel", routes[1].Group)
+}
+
+func TestVersion(t *testing.T) {
+	router := New()
+	api := router.Group("api", "/api", middleware.RequestId())
+	sunset := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	v1 := api.Version(1, Deprecated(sunset.AddDate(0, -6, 0)), Sunset(sunset))
+	v2 := api.Version(2)
+	v3 := api.Version(3)
+
+	served := func(name string) httprouter.Handle {
+		return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			writer.Write([]byte(name))
+		}
+	}
+	v1.GET("/hotel", served("v1"))
+	v2.GET("/hotel", served("v2"))
+	v3.GET("/brand", served("v3"))
+
+	serve := func(path, version string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		if version != "" {
+			request.Header.Set(state.HttpHeaders().ApiVersion.String(), version)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	assert.Equal(t, "v1", serve("/api/v1/hotel", "v2").Body.String())
+	assert.Equal(t, "v2", serve("/api/hotel", "").Body.String())
+	assert.Equal(t, "v2", serve("/api/hotel", "v3").Body.String())
+	assert.Equal(t, "v2", serve("/api/hotel", "1.4.0").Body.String())
+
+	deprecated := serve("/api/hotel", "1")
+	assert.Equal(t, "v1", deprecated.Body.String())
+	assert.Equal(t, "v1", deprecated.Header().Get("Api-Version"))
+	assert.Equal(t, "Fri, 01 Jan 2027 00:00:00 GMT", deprecated.Header().Get("Sunset"))
+	assert.NotEmpty(t, deprecated.Header().Get("Deprecation"))
+	assert.Empty(t, serve("/api/hotel", "v2").Header().Get("Deprecation"))
+
+	unsupported := serve("/api/brand", "v2")
+	assert.Equal(t, http.StatusNotFound, unsupported.Code)
+	assert.NotEmpty(t, unsupported.Header().Get(state.HttpHeaders().RequestId.String()))
+
+	assert.Equal(t, []VersionedRoute{
+		{Method: http.MethodGet, Path: "/api/brand", Versions: []int{3}},
+		{Method: http.MethodGet, Path: "/api/hotel", Versions: []int{1, 2}},
+	}, router.Versions())
+	assert.Equal(t, 2, router.Routes()[1].Version)
+}
+
+func TestVersionWithClientInfo(t *testing.T) {
+	config.Set(config.Config{Client: config.ClientConfig{MinVersions: map[string]string{"android": "1.0.0"}}})
+	defer config.Set(config.Config{})
+
+	router := New()
+	api := router.Group("api", "/api", middleware.ClientInfo())
+	api.Version(1).GET("/hotel", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		writer.Write([]byte("v1"))
+	})
+
+	serve := func(apiVersion, appVersion string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/api/hotel", nil)
+		request.Header.Set(state.HttpHeaders().Platform.String(), "android")
+		request.Header.Set(state.HttpHeaders().ApiVersion.String(), apiVersion)
+		if appVersion != "" {
+			request.Header.Set(state.HttpHeaders().Version.String(), appVersion)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	served := serve("v1", "2.3.1")
+	assert.Equal(t, http.StatusOK, served.Code)
+	assert.Equal(t, "v1", served.Body.String())
+	assert.Equal(t, http.StatusUpgradeRequired, serve("v1", "").Code)
+	assert.Equal(t, http.StatusBadRequest, serve("v1", "v1").Code)
+}
@@ -1,16 +1,75 @@
 package router
 
 import (
-	"github.com/julienschmidt/httprouter"
+	config "github.com/mochammadshenna/arch-pba-template/config"
 	"github.com/mochammadshenna/arch-pba-template/internal/controller"
+	"github.com/mochammadshenna/arch-pba-template/internal/middleware"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/authorization"
 	"github.com/mochammadshenna/arch-pba-template/internal/util/exception"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/idempotency"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/ratelimit"
 )
 
-func NewRouter(customerController controller.PbaController) *httprouter.Router {
-	router := httprouter.New()
+func NewRouter(
+	customerController controller.PbaController,
+	authController controller.AuthController,
+	healthController controller.HealthController,
+	rateLimitStore ratelimit.Store,
+	idempotencyStore idempotency.Store,
+	authenticate middleware.Middleware,
+) *Router {
+	router := New()
 
-	router.GET("/api/brand", customerController.FindAllBrandHotel)
+	// use middleware.ProblemDetails() instead of NegotiateErrorFormat() for partner groups
+	api := router.Group("api", "/api",
+		middleware.Cors(),
+		middleware.RequestId(),
+		middleware.AccessLog(),
+		middleware.NegotiateErrorFormat(),
+		middleware.Maintenance(),
+		middleware.Timeout(),
+		// the api rule caps every route by IP, public search and any other new route included
+		middleware.RateLimit(rateLimitStore, "api"),
+	)
+	public := api.Group("public", "",
+		middleware.RateLimit(rateLimitStore, "public"),
+		middleware.ClientInfo(),
+		middleware.Recover(),
+		middleware.Idempotency(idempotencyStore, config.Get().Idempotency.Ttl),
+	)
+	cms := api.Group("cms", "",
+		authenticate,
+		middleware.RateLimit(rateLimitStore, "cms"),
+		middleware.ClientInfo(),
+		middleware.Recover(),
+		middleware.Idempotency(idempotencyStore, config.Get().Idempotency.Ttl),
+	)
 
+	// probes stay outside the api group, they are not rate limited nor blocked by maintenance
+	probes := router.Group("health", "", middleware.RequestId(), middleware.AccessLog())
+	probes.GET("/healthz", healthController.Liveness, Name("health.liveness"))
+	probes.GET("/readyz", healthController.Readiness, Name("health.readiness"))
+
+	public.POST("/auth/login", authController.Login, Name("auth.login"))
+	public.POST("/auth/refresh", authController.Refresh, Name("auth.refresh"))
+	cms.POST("/auth/logout", authController.Logout, Name("auth.logout"))
+
+	// versioned routes are served on /api/v1/brand and on /api/brand picked by the Api-Version header,
+	// retire a version with cms.Version(1, Deprecated(...), Sunset(...))
+	cmsV1 := cms.Version(1)
+
+	// the brand use case has no implementation yet: the brands table only has an id and there is no
+	// repository nor service behind controller.PbaController. The route is intentionally not registered
+	// until one is given, /api/brand answers 404 meanwhile and the container logs a warning at startup.
+	// Long-running routes like exports opt out of the request deadline with Timeout(middleware.NoTimeout).
+	if customerController != nil {
+		cmsV1.GET("/brand", customerController.FindAllBrandHotel,
+			Name("brand.list"),
+			Permissions(authorization.PermissionBrandRead),
+		)
+	}
+
+	router.GlobalOPTIONS = middleware.CorsPreflight()
 	router.PanicHandler = exception.ErrorHandler
 
 	return router
@@ -0,0 +1,143 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mochammadshenna/arch-pba-template/internal/middleware"
+	"github.com/mochammadshenna/arch-pba-template/internal/model/api"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exceptioncode"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/httphelper"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/version"
+)
+
+// VersionedRoute lists the API versions serving a route, Path is the unversioned path.
+type VersionedRoute struct {
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Versions []int  `json:"versions"`
+}
+
+type VersionOption func(*apiVersion)
+
+// Deprecated sends the Deprecation header on every response of the version.
+func Deprecated(at time.Time) VersionOption {
+	return func(v *apiVersion) {
+		v.deprecatedAt = at
+	}
+}
+
+// Sunset sends the Sunset header with the date the version is removed.
+func Sunset(at time.Time) VersionOption {
+	return func(v *apiVersion) {
+		v.sunsetAt = at
+	}
+}
+
+type apiVersion struct {
+	version      int
+	deprecatedAt time.Time
+	sunsetAt     time.Time
+}
+
+// versionedHandles keeps the handle of every version registered for one unversioned route.
+// unsupported answers a version without a handle through the chain of the unversioned group, so
+// the 404 gets the CORS and Request-Id headers like any other response.
+type versionedHandles struct {
+	byVersion   map[int]httprouter.Handle
+	unsupported httprouter.Handle
+}
+
+// Version creates a sub group under /v<version>. Its routes are also served on the unversioned
+// path of g, where the Api-Version header picks the version, e.g. Api-Version: v1 on /api/brand
+// serves /api/v1/brand. Without the header the newest version is served. The Version header stays
+// the app version checked by middleware.ClientInfo.
+func (g *Group) Version(v int, options ...VersionOption) *Group {
+	info := apiVersion{version: v}
+	for _, option := range options {
+		option(&info)
+	}
+
+	group := g.Group(fmt.Sprintf("%s.v%d", g.name, v), fmt.Sprintf("/v%d", v),
+		middleware.ApiVersion(v, info.deprecatedAt, info.sunsetAt),
+	)
+	group.version = v
+	group.unversionedPrefix = g.prefix
+	group.unversionedMiddlewares = with(g.middlewares)
+	return group
+}
+
+// Versions lists the unversioned routes with the API versions serving them, ordered by path and method.
+func (r *Router) Versions() []VersionedRoute {
+	routes := []VersionedRoute{}
+	for key, handles := range r.versioned {
+		versions := make([]int, 0, len(handles.byVersion))
+		for v := range handles.byVersion {
+			versions = append(versions, v)
+		}
+		sort.Ints(versions)
+		routes = append(routes, VersionedRoute{Method: key.method, Path: key.path, Versions: versions})
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+type routeKey struct {
+	method string
+	path   string
+}
+
+func (r *Router) handleVersioned(method, path string, v int, handle httprouter.Handle, middlewares []middleware.Middleware) {
+	key := routeKey{method: method, path: path}
+	if r.versioned == nil {
+		r.versioned = map[routeKey]*versionedHandles{}
+	}
+
+	handles, ok := r.versioned[key]
+	if !ok {
+		handles = &versionedHandles{
+			byVersion:   map[int]httprouter.Handle{},
+			unsupported: middleware.Chain(unsupportedVersion, with([]middleware.Middleware{middleware.Route(path)}, middlewares...)...),
+		}
+		r.versioned[key] = handles
+		r.Router.Handle(method, path, handles.dispatch)
+	}
+	handles.byVersion[v] = handle
+}
+
+// dispatch serves the requested version, or the newest older one when the route did not change
+// in the requested version.
+func (h *versionedHandles) dispatch(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	requested, hasVersion := version.ParseApiVersion(request.Header.Get(state.HttpHeaders().ApiVersion.String()))
+
+	selected := 0
+	for v := range h.byVersion {
+		if (!hasVersion || v <= requested) && v > selected {
+			selected = v
+		}
+	}
+
+	if selected == 0 {
+		h.unsupported(writer, request, params)
+		return
+	}
+
+	h.byVersion[selected](writer, request, params)
+}
+
+func unsupportedVersion(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	requested, _ := version.ParseApiVersion(request.Header.Get(state.HttpHeaders().ApiVersion.String()))
+	httphelper.WriteErrorStatus(request.Context(), writer, http.StatusNotFound, api.ErrorResponse{
+		Code:    exceptioncode.CodeUnsupportedVersion,
+		Message: fmt.Sprintf("route is not available in API version v%d", requested),
+	})
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/mochammadshenna/arch-pba-template/internal/model/api"
+	"github.com/mochammadshenna/arch-pba-template/internal/repository"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exceptioncode"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/helper"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/password"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/token"
+)
+
+// unknownUserPasswordHash is checked when the username does not exist,
+// so the response time does not tell which usernames are registered.
+const unknownUserPasswordHash = "$2a$14$7KQEWhwoxnnm8ID3ysNNUefkFAv8A7ae7.OkB.LLmq9pSVbkUHyyG"
+
+type AuthService interface {
+	Login(ctx context.Context, request api.LoginRequest) api.TokenResponse
+	Refresh(ctx context.Context, request api.RefreshTokenRequest) api.TokenResponse
+	Logout(ctx context.Context, claims token.Claims, request api.LogoutRequest)
+}
+
+type AuthServiceImpl struct {
+	DB             *sql.DB
+	UserRepository repository.UserRepository
+	RoleRepository repository.RoleRepository
+	TokenManager   *token.Manager
+	TokenStore     token.Store
+}
+
+func NewAuthService(db *sql.DB, userRepository repository.UserRepository, roleRepository repository.RoleRepository, tokenManager *token.Manager, tokenStore token.Store) AuthService {
+	return &AuthServiceImpl{
+		DB:             db,
+		UserRepository: userRepository,
+		RoleRepository: roleRepository,
+		TokenManager:   tokenManager,
+		TokenStore:     tokenStore,
+	}
+}
+
+func (service *AuthServiceImpl) Login(ctx context.Context, request api.LoginRequest) api.TokenResponse {
+	tx, err := service.DB.Begin()
+	helper.PanicError(err)
+	defer helper.CommitOrRollback(tx)
+
+	user, err := service.UserRepository.FindByUsername(ctx, tx, request.Username)
+	if err != nil && err != exceptioncode.ErrEmptyResult {
+		panic(err)
+	}
+
+	passwordHash := user.Password
+	if err == exceptioncode.ErrEmptyResult {
+		passwordHash = unknownUserPasswordHash
+	}
+
+	if password.CheckHashPassword(ctx, request.Password, passwordHash) != nil || err != nil {
+		panic(exceptioncode.ErrorUnauthorized{ErrorMessage: "invalid username or password"})
+	}
+
+	return service.issueTokens(ctx, tx, user.Id)
+}
+
+func (service *AuthServiceImpl) Refresh(ctx context.Context, request api.RefreshTokenRequest) api.TokenResponse {
+	refreshToken, ok, err := service.TokenStore.ConsumeRefreshToken(ctx, token.HashRefreshToken(request.RefreshToken))
+	helper.PanicOnErrorContext(ctx, err)
+	if !ok {
+		panic(exceptioncode.ErrorUnauthorized{ErrorMessage: "invalid or expired refresh token"})
+	}
+
+	tx, err := service.DB.Begin()
+	helper.PanicError(err)
+	defer helper.CommitOrRollback(tx)
+
+	user, err := service.UserRepository.FindById(ctx, tx, refreshToken.UserId)
+	if err == exceptioncode.ErrEmptyResult {
+		panic(exceptioncode.ErrorUnauthorized{ErrorMessage: "invalid or expired refresh token"})
+	}
+	helper.PanicOnErrorContext(ctx, err)
+
+	return service.issueTokens(ctx, tx, user.Id)
+}
+
+func (service *AuthServiceImpl) Logout(ctx context.Context, claims token.Claims, request api.LogoutRequest) {
+	err := service.TokenStore.Revoke(ctx, claims.ID, claims.ExpiresAt.Time)
+	helper.PanicOnErrorContext(ctx, err)
+
+	if request.RefreshToken != "" {
+		_, _, err = service.TokenStore.ConsumeRefreshToken(ctx, token.HashRefreshToken(request.RefreshToken))
+		helper.PanicOnErrorContext(ctx, err)
+	}
+}
+
+func (service *AuthServiceImpl) issueTokens(ctx context.Context, tx *sql.Tx, userId int64) api.TokenResponse {
+	roles, err := service.RoleRepository.FindRoleNamesByUserId(ctx, tx, userId)
+	helper.PanicOnErrorContext(ctx, err)
+
+	permissions, err := service.RoleRepository.FindPermissionNamesByUserId(ctx, tx, userId)
+	helper.PanicOnErrorContext(ctx, err)
+
+	accessToken, _, err := service.TokenManager.IssueAccessToken(strconv.FormatInt(userId, 10), roles, permissions)
+	helper.PanicOnErrorContext(ctx, err)
+
+	refreshToken, err := service.TokenManager.NewRefreshToken()
+	helper.PanicOnErrorContext(ctx, err)
+
+	err = service.TokenStore.SaveRefreshToken(ctx, token.HashRefreshToken(refreshToken), token.RefreshToken{
+		UserId:    userId,
+		ExpiresAt: time.Now().Add(service.TokenManager.RefreshTokenTtl()),
+	})
+	helper.PanicOnErrorContext(ctx, err)
+
+	return api.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(service.TokenManager.AccessTokenTtl().Seconds()),
+	}
+}
@@ -20,3 +20,13 @@ func init() {
 func SetEnv(env string) {
 	App.Environment = env
 }
+
+// ShowsInternalErrors reports whether raw internal errors may be sent to clients. It fails closed,
+// only the listed environments show them, an unset or unknown APP_ENV is treated as production.
+func (a AppWrapper) ShowsInternalErrors() bool {
+	switch a.Environment {
+	case "local", "stg":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package state
+
+import "github.com/mochammadshenna/arch-pba-template/internal/util/version"
+
+type PlatformType string
+
+const (
+	PlatformTypeMobile PlatformType = "mobile"
+	PlatformTypeWeb    PlatformType = "web"
+	PlatformTypeCms    PlatformType = "cms"
+)
+
+var PlatformTypes = []PlatformType{PlatformTypeMobile, PlatformTypeWeb, PlatformTypeCms}
+
+type Platform string
+
+const (
+	PlatformAndroid Platform = "android"
+	PlatformIos     Platform = "ios"
+	PlatformWeb     Platform = "web"
+)
+
+var Platforms = []Platform{PlatformAndroid, PlatformIos, PlatformWeb}
+
+// ClientInfo is parsed from the Platform-Type, Platform and Version request headers.
+// Version is nil when the client did not send one.
+type ClientInfo struct {
+	PlatformType PlatformType
+	Platform     Platform
+	Version      *version.Version
+}
+
+func (c ClientInfo) VersionString() string {
+	if c.Version == nil {
+		return ""
+	}
+	return c.Version.String()
+}
@@ -0,0 +1,68 @@
+package state
+
+import (
+	"context"
+	"time"
+
+	"github.com/mochammadshenna/arch-pba-template/internal/util/token"
+)
+
+type contextKey int
+
+const (
+	requestIdKey contextKey = iota
+	routePatternKey
+	clientInfoKey
+	claimsKey
+	routeTimeoutKey
+)
+
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey, requestId)
+}
+
+// RequestId returns the request ID of the context, or an empty string when there is none.
+func RequestId(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdKey).(string)
+	return requestId
+}
+
+func WithRoutePattern(ctx context.Context, pattern string) context.Context {
+	return context.WithValue(ctx, routePatternKey, pattern)
+}
+
+// RoutePattern returns the registered path of the matched route, e.g. /api/hotel/:id
+func RoutePattern(ctx context.Context) string {
+	pattern, _ := ctx.Value(routePatternKey).(string)
+	return pattern
+}
+
+func WithClientInfo(ctx context.Context, clientInfo ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey, clientInfo)
+}
+
+// GetClientInfo returns the client of the request, the zero value when the headers were not parsed.
+func GetClientInfo(ctx context.Context) ClientInfo {
+	clientInfo, _ := ctx.Value(clientInfoKey).(ClientInfo)
+	return clientInfo
+}
+
+func WithClaims(ctx context.Context, claims token.Claims) context.Context {
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+// GetClaims returns the claims of the authenticated caller, false when the request is not authenticated.
+func GetClaims(ctx context.Context) (token.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey).(token.Claims)
+	return claims, ok
+}
+
+func WithRouteTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, routeTimeoutKey, timeout)
+}
+
+// RouteTimeout returns the timeout of the route, false when the route uses the server default.
+func RouteTimeout(ctx context.Context) (time.Duration, bool) {
+	timeout, ok := ctx.Value(routeTimeoutKey).(time.Duration)
+	return timeout, ok
+}
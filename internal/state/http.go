@@ -13,12 +13,14 @@ type HttpContentWrapper struct {
 }
 
 type httpContentTypeValues struct {
-	ApplicationJson string
+	ApplicationJson        string
+	ApplicationProblemJson string
 }
 
 func newHttpContentTypeValues() httpContentTypeValues {
 	return httpContentTypeValues{
-		ApplicationJson: "application/json",
+		ApplicationJson:        "application/json",
+		ApplicationProblemJson: "application/problem+json",
 	}
 }
 
@@ -40,8 +42,12 @@ type httpHeaders struct {
 	PlatformType  httpHeader
 	Platform      httpHeader
 	Version       httpHeader
+	ApiVersion    httpHeader
 	CacheControl  httpHeader
 	Accept        httpHeader
+
+	IdempotencyKey     httpHeader
+	IdempotentReplayed httpHeader
 }
 
 func newHttpHeaders() httpHeaders {
@@ -51,10 +57,14 @@ func newHttpHeaders() httpHeaders {
 		PlatformType:  "Platform-Type",
 		Platform:      "Platform",
 		Version:       "Version",
+		ApiVersion:    "Api-Version",
 		StartTime:     "Start-Time",
 		RequestId:     "Request-Id",
 		CacheControl:  "Cache-Control",
 		Accept:        "Accept",
+
+		IdempotencyKey:     "Idempotency-Key",
+		IdempotentReplayed: "Idempotent-Replayed",
 	}
 }
 
@@ -4,35 +4,45 @@ import (
 	"bytes"
 	"context"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/mochammadshenna/arch-pba-template/internal/outbound"
 	"github.com/mochammadshenna/arch-pba-template/internal/state"
 	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
 )
 
+var (
+	client  = outbound.NewHttpClient(0)
+	pending sync.WaitGroup
+)
+
 func Error(ctx context.Context, err error, webhookUrl, alertName, payload string, additionalData []byte) {
-	// requestId := ctx.Value(state.HttpHeaders().RequestId)
-	platformType := ctx.Value(state.HttpHeaders().PlatformType)
-	version := ctx.Value(state.HttpHeaders().Version)
+	requestId := state.RequestId(ctx)
+	clientInfo := state.GetClientInfo(ctx)
+	platformType := string(clientInfo.PlatformType)
+	version := clientInfo.VersionString()
 
-	if platformType == nil || platformType == "" {
+	if platformType == "" {
 		platformType = "unknown"
 	}
 
-	if version == nil || version == "" {
+	if version == "" {
 		version = "unknown"
 	}
 
 	// requestIdMessage := "unknown"
-	// if requestId != nil && requestId != "" {
+	// if requestId != "" {
 	// 	requestIdMessage = fmt.Sprintf(requestIdTemplate, requestId)
 	// }
 
 	// additionalDataStr := strings.ReplaceAll(string(additionalData), `"`, `\"`)
 	// payload := fmt.Sprintf(payload, time.Now().Unix(), requestIdMessage, platformType, version, err.Error(), additionalDataStr)
 
+	pending.Add(1)
 	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		defer pending.Done()
+		ctx, cancel := context.WithTimeout(state.WithRequestId(context.Background(), requestId), time.Second*30)
 		defer cancel()
 		defer func() {
 			if r := recover(); r != nil {
@@ -40,11 +50,36 @@ func Error(ctx context.Context, err error, webhookUrl, alertName, payload string
 				return
 			}
 		}()
-		_, err := http.DefaultClient.Post(webhookUrl, "application/json", bytes.NewBuffer([]byte(payload)))
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookUrl, bytes.NewBuffer([]byte(payload)))
+		if err != nil {
+			logger.Errorf(ctx, "got an error while creating google chat alerting request on alert.Error(); err=%+v", err)
+			return
+		}
+		request.Header.Set(state.HttpHeaders().ContentType.String(), state.HttpContentTypeValues().ApplicationJson)
+
+		response, err := client.Do(request)
 		if err != nil {
 			logger.Errorf(ctx, "got an error while sending google chat alerting on alert.Error(); err=%+v", err)
+			return
 		}
+		response.Body.Close()
 	}()
 }
 
+// Wait blocks until the alerts being sent are done or ctx is done, it is called on shutdown.
+func Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // const requestIdTemplate = `<https:https://ap-southeast-1.console.aws.amazon.com/cloudwatch/home?region=ap-southeast-1#logsV2:log-groups/log-group/$252Faws$252Flambda$252FPBA-API-Template/log-events/2024$252F04$252F15$252F$255B$2524LATEST$%s`
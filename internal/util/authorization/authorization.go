@@ -0,0 +1,54 @@
+package authorization
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/array"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exceptioncode"
+)
+
+// Permission names match the permissions table.
+type Permission string
+
+const (
+	PermissionBrandRead   Permission = "brand:read"
+	PermissionBrandManage Permission = "brand:manage"
+	PermissionHotelRead   Permission = "hotel:read"
+	PermissionHotelWrite  Permission = "hotel:write"
+	PermissionHotelManage Permission = "hotel:manage"
+)
+
+func HasPermission(ctx context.Context, permission Permission) bool {
+	claims, ok := state.GetClaims(ctx)
+	return ok && array.InArray(string(permission), claims.Permissions)
+}
+
+// MissingPermissions returns the permissions the caller does not have.
+func MissingPermissions(ctx context.Context, permissions ...Permission) []Permission {
+	missing := []Permission{}
+	for _, permission := range permissions {
+		if !HasPermission(ctx, permission) {
+			missing = append(missing, permission)
+		}
+	}
+	return missing
+}
+
+// CheckOwnership panics with exceptioncode.ErrorForbidden unless the caller owns the resource
+// or has the bypass permission, e.g.
+//
+//	authorization.CheckOwnership(ctx, hotel.UserId, authorization.PermissionHotelManage)
+func CheckOwnership(ctx context.Context, ownerUserId int64, bypass Permission) {
+	if HasPermission(ctx, bypass) {
+		return
+	}
+
+	claims, ok := state.GetClaims(ctx)
+	if ok && claims.Subject == strconv.FormatInt(ownerUserId, 10) {
+		return
+	}
+
+	panic(exceptioncode.ErrorForbidden{ErrorMessage: "you do not have access to this resource"})
+}
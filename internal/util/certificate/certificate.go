@@ -0,0 +1,107 @@
+package certificate
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
+)
+
+const checkInterval = 10 * time.Second
+
+var tlsVersions = map[string]uint16{
+	"":    tls.VersionTLS12,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TlsVersion parses server.tls.minVersion, empty defaults to TLS 1.2.
+func TlsVersion(version string) (uint16, error) {
+	v, ok := tlsVersions[version]
+	if !ok {
+		return 0, fmt.Errorf("unsupported tls version %q, expected 1.2 or 1.3", version)
+	}
+	return v, nil
+}
+
+// Reloader serves the certificate of certFile and keyFile and loads them again when their
+// modification time changes, so a renewed certificate is used without a restart. The files are
+// checked at most every 10 seconds during handshakes, a pair that fails to load keeps the old one.
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mu          sync.Mutex
+	certificate *tls.Certificate
+	modTime     time.Time
+	checkedAt   time.Time
+	interval    time.Duration
+	now         func() time.Time
+}
+
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: checkInterval,
+		now:      time.Now,
+	}
+
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate is meant for tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if now.Sub(r.checkedAt) >= r.interval {
+		r.checkedAt = now
+		if modTime, err := r.latestModTime(); err == nil && !modTime.Equal(r.modTime) {
+			// the current certificate stays in use until the files are fixed
+			if err := r.load(modTime); err != nil {
+				logger.Errorf(context.Background(), "failed to reload tls certificate; err=%+v", err)
+			} else {
+				logger.Infof(context.Background(), "reloaded tls certificate %s", r.certFile)
+			}
+		}
+	}
+
+	return r.certificate, nil
+}
+
+func (r *Reloader) load(modTime time.Time) error {
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load tls certificate %s: %w", r.certFile, err)
+	}
+	r.certificate = &certificate
+	r.modTime = modTime
+	return nil
+}
+
+func (r *Reloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,76 @@
+package certificate
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeCertificate(t *testing.T, dir, commonName string, modTime time.Time) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	assert.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	assert.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+	return certFile, keyFile
+}
+
+func commonName(t *testing.T, certificate *tls.Certificate) string {
+	parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+	assert.NoError(t, err)
+	return parsed.Subject.CommonName
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Now().Add(-time.Hour)
+	certFile, keyFile := writeCertificate(t, dir, "old", modTime)
+
+	reloader, err := NewReloader(certFile, keyFile)
+	assert.NoError(t, err)
+	reloader.interval = 0
+
+	certificate, _ := reloader.GetCertificate(nil)
+	assert.Equal(t, "old", commonName(t, certificate))
+
+	writeCertificate(t, dir, "renewed", modTime.Add(time.Minute))
+	certificate, _ = reloader.GetCertificate(nil)
+	assert.Equal(t, "renewed", commonName(t, certificate))
+
+	assert.NoError(t, os.WriteFile(keyFile, []byte("broken"), 0o600))
+	certificate, _ = reloader.GetCertificate(nil)
+	assert.Equal(t, "renewed", commonName(t, certificate), "a broken pair keeps the loaded certificate")
+}
+
+func TestTlsVersion(t *testing.T) {
+	v, err := TlsVersion("1.3")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), v)
+
+	_, err = TlsVersion("1.0")
+	assert.Error(t, err)
+}
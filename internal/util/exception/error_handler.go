@@ -2,28 +2,85 @@ package exception
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
+	"runtime/debug"
 
 	"github.com/go-playground/validator"
+	validatorv10 "github.com/go-playground/validator/v10"
+	config "github.com/mochammadshenna/arch-pba-template/config"
 	"github.com/mochammadshenna/arch-pba-template/internal/model/api"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/alert"
 	"github.com/mochammadshenna/arch-pba-template/internal/util/exceptioncode"
 	"github.com/mochammadshenna/arch-pba-template/internal/util/httphelper"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/json"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
+	"github.com/sirupsen/logrus"
 )
 
+// codeStatus is the http status of an api.ErrorResponse raised with panic, unknown codes are 400.
+var codeStatus = map[string]int{
+	exceptioncode.CodeDataNotFound:         http.StatusNotFound,
+	exceptioncode.CodeUnauthorized:         http.StatusUnauthorized,
+	exceptioncode.CodeForbidden:            http.StatusForbidden,
+	exceptioncode.CodeConflict:             http.StatusConflict,
+	exceptioncode.CodeRequestInProgress:    http.StatusConflict,
+	exceptioncode.CodeIdempotencyKeyReused: http.StatusUnprocessableEntity,
+	exceptioncode.CodeUpgradeRequired:      http.StatusUpgradeRequired,
+	exceptioncode.CodeTooManyRequests:      http.StatusTooManyRequests,
+	exceptioncode.CodeRequestTimeout:       http.StatusServiceUnavailable,
+	exceptioncode.CodeInternalServerError:  http.StatusInternalServerError,
+}
+
+// Panic carries a panic recovered on another goroutine to ErrorHandler with the stack where it was
+// raised, re-panicking the bare value would only keep the stack of the re-panic.
+type Panic struct {
+	Value interface{}
+	Stack []byte
+}
+
 func ErrorHandler(writer http.ResponseWriter, request *http.Request, err interface{}) {
-	if isDataNotFoundError(request.Context(), writer, err) {
+	ctx := request.Context()
+	stack := debug.Stack()
+	if p, ok := err.(Panic); ok {
+		err, stack = p.Value, p.Stack
+	}
+	if httphelper.AcceptsProblemDetails(request) {
+		ctx = httphelper.WithProblemDetails(ctx)
+	}
+
+	if isDataNotFoundError(ctx, writer, err) {
+		return
+	}
+
+	if isValidationError(ctx, writer, err) {
+		return
+	}
+
+	if isErrorForeignKeyViolation(ctx, writer, err) {
+		return
+	}
+
+	if isUnauthorizedError(ctx, writer, err) {
 		return
 	}
 
-	if isValidationError(request.Context(), writer, err) {
+	if isForbiddenError(ctx, writer, err) {
 		return
 	}
 
-	if isErrorForeignKeyViolation(request.Context(), writer, err) {
+	if isErrorResponse(ctx, writer, err) {
 		return
 	}
 
-	writeResponse(request.Context(), writer, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", err)
+	if isKnownError(ctx, writer, err) {
+		return
+	}
+
+	writeInternalServerError(ctx, writer, request, err, stack)
 }
 
 func isDataNotFoundError(ctx context.Context, writer http.ResponseWriter, err interface{}) bool {
@@ -41,6 +98,24 @@ func isValidationError(ctx context.Context, writer http.ResponseWriter, err inte
 		writeResponse(ctx, writer, http.StatusBadRequest, "BAD_REQUEST", exception.Error())
 		return true
 	}
+
+	var exceptionv10 validatorv10.ValidationErrors
+	if e, ok := err.(error); ok && errors.As(e, &exceptionv10) {
+		errs := []api.ErrorValidate{}
+		for _, er := range exceptionv10 {
+			errs = append(errs, api.ErrorValidate{
+				Key:     er.Field(),
+				Code:    "VALIDATION",
+				Message: er.Error(),
+			})
+		}
+		httphelper.WriteErrorStatus(ctx, writer, http.StatusBadRequest, api.ErrorResponse{
+			Code:    exceptioncode.CodeInvalidValidation,
+			Message: "validation error",
+			Errors:  errs,
+		})
+		return true
+	}
 	return false
 }
 
@@ -53,14 +128,117 @@ func isErrorForeignKeyViolation(ctx context.Context, writer http.ResponseWriter,
 	return false
 }
 
-func writeResponse(ctx context.Context, writer http.ResponseWriter, httpStatus int, errorCode string, err interface{}) {
-	writer.Header().Set("Content-Type", "application/json")
-	writer.WriteHeader(httpStatus)
+func isUnauthorizedError(ctx context.Context, writer http.ResponseWriter, err interface{}) bool {
+	exception, ok := err.(exceptioncode.ErrorUnauthorized)
+	if ok {
+		writeResponse(ctx, writer, http.StatusUnauthorized, exceptioncode.CodeUnauthorized, exception.ErrorMessage)
+		return true
+	}
+	return false
+}
+
+func isForbiddenError(ctx context.Context, writer http.ResponseWriter, err interface{}) bool {
+	exception, ok := err.(exceptioncode.ErrorForbidden)
+	if ok {
+		logger.Warnf(ctx, "permission denied; route=%s err=%s", state.RoutePattern(ctx), exception.ErrorMessage)
+		writeResponse(ctx, writer, http.StatusForbidden, exceptioncode.CodeForbidden, exception.ErrorMessage)
+		return true
+	}
+	return false
+}
+
+// isErrorResponse writes an api.ErrorResponse as is, with the status of its code.
+func isErrorResponse(ctx context.Context, writer http.ResponseWriter, err interface{}) bool {
+	e, ok := err.(error)
+	if !ok {
+		return false
+	}
+
+	var exception api.ErrorResponse
+	if !errors.As(e, &exception) {
+		return false
+	}
+
+	status, ok := codeStatus[exception.Code]
+	if !ok {
+		status = http.StatusBadRequest
+	}
+	if status == http.StatusInternalServerError {
+		return false
+	}
+
+	httphelper.WriteErrorStatus(ctx, writer, status, exception)
+	return true
+}
+
+// isKnownError maps the sentinel errors of exceptioncode, also when they are wrapped.
+func isKnownError(ctx context.Context, writer http.ResponseWriter, err interface{}) bool {
+	e, ok := err.(error)
+	if !ok {
+		return false
+	}
+
+	switch {
+	case errors.Is(e, exceptioncode.ErrEmptyResult), errors.Is(e, sql.ErrNoRows):
+		writeResponse(ctx, writer, http.StatusNotFound, exceptioncode.CodeDataNotFound, e.Error())
+	case errors.Is(e, exceptioncode.ErrInvalidRequest), errors.Is(e, exceptioncode.ErrForeignKeyViolation):
+		writeResponse(ctx, writer, http.StatusBadRequest, exceptioncode.CodeInvalidRequest, e.Error())
+	case errors.Is(e, exceptioncode.ErrUniqueViolation):
+		writeResponse(ctx, writer, http.StatusConflict, exceptioncode.CodeConflict, e.Error())
+	case errors.Is(e, context.DeadlineExceeded):
+		writeResponse(ctx, writer, http.StatusServiceUnavailable, exceptioncode.CodeRequestTimeout, "request timed out")
+	default:
+		return false
+	}
+	return true
+}
 
+// writeInternalServerError logs the panic with its stack and alerts. In production the client
+// only gets a generic message with the request ID to report.
+func writeInternalServerError(ctx context.Context, writer http.ResponseWriter, request *http.Request, err interface{}, stack []byte) {
+	requestId := state.RequestId(ctx)
+	if requestId == "" {
+		requestId = writer.Header().Get(state.HttpHeaders().RequestId.String())
+	}
+
+	logger.WithFields(ctx, logrus.Fields{
+		logger.LoggerField().RequestId:     requestId,
+		logger.LoggerField().RequestMethod: request.Method,
+		logger.LoggerField().Resource:      state.RoutePattern(ctx),
+		logger.LoggerField().Path:          request.URL.Path,
+		logger.LoggerField().Stack:         string(stack),
+	}).Errorf("panic recovered: %v", err)
+
+	sendAlert(ctx, request, requestId, err)
+
+	message := fmt.Sprint(err)
+	if !state.App.ShowsInternalErrors() {
+		message = fmt.Sprintf("internal server error, please contact support with request id %s", requestId)
+	}
+	writeResponse(ctx, writer, http.StatusInternalServerError, exceptioncode.CodeInternalServerError, message)
+}
+
+func sendAlert(ctx context.Context, request *http.Request, requestId string, err interface{}) {
+	webhookUrl := config.Get().Alert.WebhookUrl
+	if webhookUrl == "" {
+		return
+	}
+
+	text := fmt.Sprintf("[%s] panic on %s %s\nrequest id: %s\n%v", state.App.Environment, request.Method, request.URL.Path, requestId, err)
+	payload, marshalErr := json.Marshal(map[string]string{"text": text})
+	if marshalErr != nil {
+		logger.Error(ctx, marshalErr)
+		return
+	}
+
+	alert.Error(ctx, fmt.Errorf("%v", err), webhookUrl, "panic", string(payload), nil)
+}
+
+func writeResponse(ctx context.Context, writer http.ResponseWriter, httpStatus int, errorCode string, err interface{}) {
 	errorResponse := api.ErrorResponse{
 		Code:    errorCode,
 		Message: err,
 	}
 
-	httphelper.WriteError(ctx, writer, errorResponse)
+	httphelper.WriteErrorStatus(ctx, writer, httpStatus, errorResponse)
 }
@@ -0,0 +1,59 @@
+package exception
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mochammadshenna/arch-pba-template/internal/model/api"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exceptioncode"
+	"github.com/stretchr/testify/assert"
+)
+
+func handle(err interface{}) (*httptest.ResponseRecorder, api.ErrorResponse) {
+	request := httptest.NewRequest(http.MethodGet, "/api/brand", nil)
+	recorder := httptest.NewRecorder()
+	recorder.Header().Set("Request-Id", "req-1")
+
+	ErrorHandler(recorder, request, err)
+
+	var response struct {
+		Error api.ErrorResponse `json:"error"`
+	}
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	return recorder, response.Error
+}
+
+func TestErrorHandlerMapsErrors(t *testing.T) {
+	recorder, response := handle(fmt.Errorf("hotel 1: %w", exceptioncode.ErrEmptyResult))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, exceptioncode.CodeDataNotFound, response.Code)
+
+	recorder, response = handle(api.ErrorResponse{Code: exceptioncode.CodeForbidden, Message: "no access"})
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Equal(t, "no access", response.Message)
+
+	recorder, _ = handle(exceptioncode.ErrUniqueViolation)
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+}
+
+func TestErrorHandlerHidesInternalErrorsOutsideLocalAndStg(t *testing.T) {
+	defer state.SetEnv(state.App.Environment)
+
+	state.SetEnv("stg")
+	recorder, response := handle(errors.New("Error 1146: Table 'arch_db.hotels' doesn't exist"))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Contains(t, response.Message, "arch_db.hotels")
+
+	for _, env := range []string{"prod", "production", "prd", ""} {
+		state.SetEnv(env)
+		recorder, response = handle(errors.New("Error 1146: Table 'arch_db.hotels' doesn't exist"))
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		assert.NotContains(t, response.Message, "arch_db.hotels", env)
+		assert.Contains(t, response.Message, "req-1")
+	}
+}
@@ -17,6 +17,17 @@ const (
 	CodeInvalidValidation   = "INVALID_VALIDATION"
 	CodeBadRequest          = "BAD_REQUEST"
 	CodeInternalServerError = "INTERNAL_SERVER_ERROR"
+	CodeUnauthorized        = "UNAUTHORIZED"
+	CodeForbidden           = "FORBIDDEN"
+	CodeConflict            = "CONFLICT"
+	CodeRequestTimeout      = "REQUEST_TIMEOUT"
+	CodeUnsupportedVersion  = "UNSUPPORTED_API_VERSION"
+
+	CodeUpgradeRequired      = "UPGRADE_REQUIRED"
+	CodeTooManyRequests      = "TOO_MANY_REQUESTS"
+	CodeIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+	CodeRequestInProgress    = "REQUEST_IN_PROGRESS"
+	CodeMaintenance          = "MAINTENANCE"
 )
 
 type (
@@ -25,6 +36,8 @@ type (
 	}
 	ErrorNotFound            errorType
 	ErrorForeignKeyViolation errorType
+	ErrorUnauthorized        errorType
+	ErrorForbidden           errorType
 )
 
 type NotFoundError struct {
@@ -0,0 +1,179 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+
+	DefaultTimeout  = 2 * time.Second
+	DefaultCacheTtl = 5 * time.Second
+)
+
+// Check returns nil when the dependency is usable.
+type Check func(ctx context.Context) error
+
+type Result struct {
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	CheckedAt  time.Time `json:"checkedAt"`
+}
+
+type Report struct {
+	Status string            `json:"status"`
+	Error  string            `json:"error,omitempty"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Registry runs the named readiness checks. Results are cached for cacheTtl so frequent probes
+// from several orchestrator nodes do not hammer MySQL and Redis.
+type Registry struct {
+	mu           sync.Mutex
+	checks       map[string]*namedCheck
+	timeout      time.Duration
+	cacheTtl     time.Duration
+	shuttingDown func() bool
+	now          func() time.Time
+}
+
+type namedCheck struct {
+	mu     sync.Mutex
+	name   string
+	check  Check
+	result Result
+}
+
+// NewRegistry creates a registry, readiness fails as soon as shuttingDown returns true.
+// A zero timeout or cacheTtl uses the defaults.
+func NewRegistry(timeout, cacheTtl time.Duration, shuttingDown func() bool) *Registry {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if cacheTtl <= 0 {
+		cacheTtl = DefaultCacheTtl
+	}
+	if shuttingDown == nil {
+		shuttingDown = func() bool { return false }
+	}
+
+	return &Registry{
+		checks:       map[string]*namedCheck{},
+		timeout:      timeout,
+		cacheTtl:     cacheTtl,
+		shuttingDown: shuttingDown,
+		now:          time.Now,
+	}
+}
+
+// Public returns the report without the check errors, they name hosts and addresses of the
+// dependencies and are only logged.
+func (r Report) Public() Report {
+	public := Report{Status: r.Status, Error: r.Error}
+	if r.Checks != nil {
+		public.Checks = make(map[string]Result, len(r.Checks))
+		for name, result := range r.Checks {
+			result.Error = ""
+			public.Checks[name] = result
+		}
+	}
+	return public
+}
+
+// Register adds a check, registering the same name again replaces it.
+func (r *Registry) Register(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = &namedCheck{name: name, check: check}
+}
+
+// Ready runs every check concurrently and is up only when all of them are up.
+func (r *Registry) Ready(ctx context.Context) Report {
+	if r.shuttingDown() {
+		return Report{Status: StatusDown, Error: "shutting down"}
+	}
+
+	r.mu.Lock()
+	checks := make(map[string]*namedCheck, len(r.checks))
+	for name, c := range r.checks {
+		checks[name] = c
+	}
+	r.mu.Unlock()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(checks))}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, c := range checks {
+		wg.Add(1)
+		go func(name string, c *namedCheck) {
+			defer wg.Done()
+			result := r.run(ctx, c)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}(name, c)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// run returns the cached result while it is fresh, concurrent callers wait for a single run.
+func (r *Registry) run(ctx context.Context, c *namedCheck) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	startedAt := r.now()
+	if !c.result.CheckedAt.IsZero() && startedAt.Sub(c.result.CheckedAt) < r.cacheTtl {
+		return c.result
+	}
+
+	err := r.runWithTimeout(ctx, c.check)
+	c.result = Result{
+		Status:     StatusUp,
+		DurationMs: r.now().Sub(startedAt).Milliseconds(),
+		CheckedAt:  startedAt,
+	}
+	if err != nil {
+		c.result.Status = StatusDown
+		c.result.Error = err.Error()
+		logger.Warnf(ctx, "readiness check %s is down; err=%s", c.name, c.result.Error)
+	}
+	return c.result
+}
+
+// runWithTimeout also bounds checks that ignore ctx, like the dbmate migration status.
+func (r *Registry) runWithTimeout(ctx context.Context, check Check) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("check panicked: %v", p)
+			}
+		}()
+		done <- check(ctx)
+	}()
+
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("check timed out after %s", r.timeout)
+	}
+}
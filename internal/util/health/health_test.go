@@ -0,0 +1,47 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReady(t *testing.T) {
+	calls := 0
+	shuttingDown := false
+	registry := NewRegistry(50*time.Millisecond, time.Minute, func() bool { return shuttingDown })
+	registry.Register("mysql", func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+	registry.Register("redis", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+	registry.Register("migrations", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	report := registry.Ready(context.Background())
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, StatusUp, report.Checks["mysql"].Status)
+	assert.Equal(t, "connection refused", report.Checks["redis"].Error)
+	assert.Equal(t, "check timed out after 50ms", report.Checks["migrations"].Error)
+
+	public := report.Public()
+	assert.Equal(t, StatusDown, public.Status)
+	assert.Equal(t, StatusDown, public.Checks["redis"].Status)
+	assert.Empty(t, public.Checks["redis"].Error)
+	assert.Equal(t, "connection refused", report.Checks["redis"].Error, "the report is not modified")
+
+	registry.Ready(context.Background())
+	assert.Equal(t, 1, calls, "results are cached")
+
+	shuttingDown = true
+	report = registry.Ready(context.Background())
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Empty(t, report.Checks)
+}
@@ -0,0 +1,340 @@
+package httphelper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mochammadshenna/arch-pba-template/internal/model/api"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exceptioncode"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
+)
+
+// BindConverter turns a raw path, query or header value into a value of the registered type.
+// Repeated query values are joined with "," before being passed to the converter.
+type BindConverter func(value string) (reflect.Value, error)
+
+var bindConverters = map[reflect.Type]BindConverter{}
+
+// bindSources is the order in which tags are looked up, a later source overrides an earlier one.
+var bindSources = []string{"query", "header", "path"}
+
+const dateLayout = "2006-01-02"
+
+func init() {
+	RegisterBindConverter(reflect.TypeOf([]int64{}), convertInt64CommaSeparated)
+	RegisterBindConverter(reflect.TypeOf(uuid.UUID{}), convertUuid)
+	RegisterBindConverter(reflect.TypeOf(time.Time{}), convertTime)
+	RegisterBindConverter(reflect.TypeOf(api.TimeRange{}), convertTimeRange)
+}
+
+// RegisterBindConverter registers a converter used by Bind for every field of type t.
+// It is not safe for concurrent use and should be called from init.
+func RegisterBindConverter(t reflect.Type, converter BindConverter) {
+	bindConverters[t] = converter
+}
+
+// RegisterEnum registers a converter that only accepts the given values for T.
+func RegisterEnum[T ~string](values ...T) {
+	allowed := make([]string, len(values))
+	for i, v := range values {
+		allowed[i] = string(v)
+	}
+
+	RegisterBindConverter(reflect.TypeOf(*new(T)), func(value string) (reflect.Value, error) {
+		for _, v := range values {
+			if string(v) == value {
+				return reflect.ValueOf(v), nil
+			}
+		}
+		return reflect.Value{}, fmt.Errorf("invalid value %q, must be one of [%s]", value, strings.Join(allowed, ", "))
+	})
+}
+
+// Bind fills result from the JSON body and from the `path`, `query` and `header` tags of its fields.
+//
+//	type UpdateHotelRequest struct {
+//		Id           int64  `path:"id"`
+//		PlatformType string `header:"Platform-Type"`
+//		Name         string `json:"name"`
+//	}
+//
+// Every field that could not be bound is reported in a single validation error.
+func Bind(request *http.Request, params httprouter.Params, result interface{}) error {
+	value := reflect.ValueOf(result)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("httphelper: Bind expects a pointer to struct, got %T", result)
+	}
+
+	errs := []api.ErrorValidate{}
+
+	if hasBody(request) {
+		err := json.NewDecoder(request.Body).Decode(result)
+		if err != nil && err != io.EOF {
+			errs = append(errs, bodyError(err))
+		}
+	}
+
+	binder := binder{
+		request: request,
+		params:  params,
+		query:   request.URL.Query(),
+	}
+	errs = append(errs, binder.bindStruct(value.Elem())...)
+
+	if len(errs) > 0 {
+		return api.ErrorResponse{
+			Code:    exceptioncode.CodeInvalidValidation,
+			Message: "validation error",
+			Errors:  errs,
+		}
+	}
+
+	logger.Info(request.Context(), strings.Replace(fmt.Sprintf("request: %+v", result), "&", "", 1))
+	return nil
+}
+
+type binder struct {
+	request *http.Request
+	params  httprouter.Params
+	query   map[string][]string
+}
+
+func (b binder) bindStruct(value reflect.Value) []api.ErrorValidate {
+	errs := []api.ErrorValidate{}
+	valueType := value.Type()
+
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		fieldValue := value.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && !hasBindTag(field) {
+			errs = append(errs, b.bindStruct(fieldValue)...)
+			continue
+		}
+
+		for _, source := range bindSources {
+			key := field.Tag.Get(source)
+			if key == "" || key == "-" {
+				continue
+			}
+
+			values := b.lookup(source, key)
+			if len(values) == 0 {
+				continue
+			}
+
+			if err := setField(fieldValue, values); err != nil {
+				errs = append(errs, api.ErrorValidate{
+					Key:     key,
+					Code:    "VALIDATION",
+					Message: err.Error(),
+				})
+			}
+		}
+	}
+
+	return errs
+}
+
+func (b binder) lookup(source, key string) []string {
+	switch source {
+	case "path":
+		if v := b.params.ByName(key); v != "" {
+			return []string{v}
+		}
+	case "query":
+		return b.query[key]
+	case "header":
+		return b.request.Header.Values(key)
+	}
+	return nil
+}
+
+func setField(field reflect.Value, values []string) error {
+	if converter, ok := bindConverters[field.Type()]; ok {
+		v, err := converter(strings.Join(values, ","))
+		if err != nil {
+			return err
+		}
+		if v.Type() != field.Type() {
+			v = v.Convert(field.Type())
+		}
+		field.Set(v)
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.Pointer:
+		ptr := reflect.New(field.Type().Elem())
+		if err := setField(ptr.Elem(), values); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	case reflect.Slice:
+		items := splitCommaSeparated(values)
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setField(slice.Index(i), []string{item}); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	return setScalar(field, values[0])
+}
+
+func setScalar(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean value %q", value)
+		}
+		field.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer value %q", value)
+		}
+		field.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer value %q", value)
+		}
+		field.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number value %q", value)
+		}
+		field.SetFloat(v)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+func convertInt64CommaSeparated(value string) (reflect.Value, error) {
+	items := splitCommaSeparated([]string{value})
+	result := make([]int64, len(items))
+	for i, item := range items {
+		v, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid integer value %q", item)
+		}
+		result[i] = v
+	}
+	return reflect.ValueOf(result), nil
+}
+
+func convertUuid(value string) (reflect.Value, error) {
+	v, err := uuid.Parse(value)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("invalid uuid value %q", value)
+	}
+	return reflect.ValueOf(v), nil
+}
+
+func convertTime(value string) (reflect.Value, error) {
+	v, err := parseTime(value)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(v), nil
+}
+
+// convertTimeRange parses "from,to" where both ends are RFC3339 or dates.
+// A date-only upper bound covers the whole day.
+func convertTimeRange(value string) (reflect.Value, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return reflect.Value{}, fmt.Errorf("invalid time range %q, expected from,to", value)
+	}
+
+	from, err := parseTime(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	rawTo := strings.TrimSpace(parts[1])
+	to, err := parseTime(rawTo)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if len(rawTo) == len(dateLayout) {
+		to = to.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	if to.Before(from) {
+		return reflect.Value{}, fmt.Errorf("invalid time range %q, from is after to", value)
+	}
+
+	return reflect.ValueOf(api.TimeRange{From: from, To: to}), nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if v, err := time.Parse(time.RFC3339, value); err == nil {
+		return v, nil
+	}
+	if v, err := time.Parse(dateLayout, value); err == nil {
+		return v, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time value %q, expected RFC3339 or %s", value, dateLayout)
+}
+
+func splitCommaSeparated(values []string) []string {
+	result := []string{}
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
+func hasBindTag(field reflect.StructField) bool {
+	for _, source := range bindSources {
+		if field.Tag.Get(source) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasBody(request *http.Request) bool {
+	return request.Body != nil &&
+		(request.Method == http.MethodPost || request.Method == http.MethodPut || request.Method == http.MethodPatch)
+}
+
+func bodyError(err error) api.ErrorValidate {
+	key := "body"
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		key = typeErr.Field
+	}
+	return api.ErrorValidate{
+		Key:     key,
+		Code:    "VALIDATION",
+		Message: err.Error(),
+	}
+}
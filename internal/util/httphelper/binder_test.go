@@ -0,0 +1,72 @@
+package httphelper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mochammadshenna/arch-pba-template/internal/model/api"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exceptioncode"
+	"github.com/stretchr/testify/assert"
+)
+
+type bindStatus string
+
+func init() {
+	RegisterEnum[bindStatus]("active", "inactive")
+}
+
+type bindRequest struct {
+	Id           int64         `path:"id"`
+	Ids          []int64       `query:"ids"`
+	Status       bindStatus    `query:"status"`
+	Date         api.TimeRange `query:"date"`
+	Token        uuid.UUID     `query:"token"`
+	Page         *int          `query:"page"`
+	PlatformType string        `header:"Platform-Type"`
+	Name         string        `json:"name"`
+}
+
+func TestBind(t *testing.T) {
+	token := uuid.New()
+	request := httptest.NewRequest(http.MethodPatch,
+		"/api/hotel/10?ids=1,2&ids=3&status=active&date=2023-10-01,2023-10-31&page=2&token="+token.String(),
+		strings.NewReader(`{"name":"Aston"}`))
+	request.Header.Set("Platform-Type", "cms")
+	params := httprouter.Params{{Key: "id", Value: "10"}}
+
+	var result bindRequest
+	err := Bind(request, params, &result)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), result.Id)
+	assert.Equal(t, []int64{1, 2, 3}, result.Ids)
+	assert.Equal(t, bindStatus("active"), result.Status)
+	assert.Equal(t, token, result.Token)
+	assert.Equal(t, 2, *result.Page)
+	assert.Equal(t, "cms", result.PlatformType)
+	assert.Equal(t, "Aston", result.Name)
+	assert.True(t, result.Date.Contains(time.Date(2023, 10, 31, 23, 0, 0, 0, time.UTC)))
+}
+
+func TestBindReportsEveryInvalidField(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/api/hotel/x?ids=1,a&status=deleted&date=2023-10-31,2023-10-01&token=1", nil)
+	params := httprouter.Params{{Key: "id", Value: "x"}}
+
+	var result bindRequest
+	err := Bind(request, params, &result)
+
+	errorResponse, ok := err.(api.ErrorResponse)
+	assert.True(t, ok)
+	assert.Equal(t, exceptioncode.CodeInvalidValidation, errorResponse.Code)
+
+	keys := []string{}
+	for _, e := range errorResponse.Errors.([]api.ErrorValidate) {
+		keys = append(keys, e.Key)
+	}
+	assert.ElementsMatch(t, []string{"id", "ids", "status", "date", "token"}, keys)
+}
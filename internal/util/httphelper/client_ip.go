@@ -0,0 +1,92 @@
+package httphelper
+
+import (
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	config "github.com/mochammadshenna/arch-pba-template/config"
+)
+
+var trustedProxies atomic.Pointer[[]*net.IPNet]
+
+func init() {
+	config.Subscribe(func(old, new config.Config) {
+		if trustedProxies.Load() == nil || !slices.Equal(old.Server.TrustedProxies, new.Server.TrustedProxies) {
+			SetTrustedProxies(new.Server.TrustedProxies)
+		}
+	})
+}
+
+// SetTrustedProxies replaces the proxies of server.trustedProxies, entries are IPs or CIDRs and
+// invalid entries are skipped, the config validation rejects them.
+func SetTrustedProxies(proxies []string) {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil {
+				bits := 8 * net.IPv6len
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 8*net.IPv4len
+				}
+				networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			}
+			continue
+		}
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	trustedProxies.Store(&networks)
+}
+
+// ClientIp returns the address of the caller. X-Forwarded-For and X-Real-Ip are only read when the
+// connection comes from a trusted proxy. The load balancer appends to X-Forwarded-For, so it is
+// walked from the right and the first hop that is not a trusted proxy is the client, anything on
+// its left was sent by the client and can be spoofed.
+func ClientIp(request *http.Request) string {
+	peer, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		peer = request.RemoteAddr
+	}
+	if !isTrustedProxy(peer) {
+		return peer
+	}
+
+	if forwardedFor := request.Header.Values("X-Forwarded-For"); len(forwardedFor) > 0 {
+		hops := strings.Split(strings.Join(forwardedFor, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				// a malformed hop was not written by our proxies, stop at the last trusted one
+				return peer
+			}
+			if !isTrustedProxy(hop) {
+				return hop
+			}
+			peer = hop
+		}
+		return peer
+	}
+
+	if realIp := strings.TrimSpace(request.Header.Get("X-Real-Ip")); net.ParseIP(realIp) != nil {
+		return realIp
+	}
+	return peer
+}
+
+func isTrustedProxy(address string) bool {
+	ip := net.ParseIP(address)
+	networks := trustedProxies.Load()
+	if ip == nil || networks == nil {
+		return false
+	}
+	for _, network := range *networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package httphelper
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIp(t *testing.T) {
+	SetTrustedProxies([]string{"10.0.0.0/8", "35.191.0.1"})
+	defer SetTrustedProxies(nil)
+
+	request := func(remoteAddr, forwardedFor, realIp string) string {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		if realIp != "" {
+			r.Header.Set("X-Real-Ip", realIp)
+		}
+		return ClientIp(r)
+	}
+
+	// headers of an untrusted peer are ignored
+	assert.Equal(t, "198.51.100.1", request("198.51.100.1:1234", "203.0.113.7", "203.0.113.7"))
+	// the load balancer appends the client to a spoofed header
+	assert.Equal(t, "198.51.100.1", request("10.0.0.2:1234", "203.0.113.7, 198.51.100.1, 35.191.0.1", ""))
+	assert.Equal(t, "198.51.100.1", request("10.0.0.2:1234", "198.51.100.1", ""))
+	assert.Equal(t, "10.0.0.3", request("10.0.0.2:1234", "not-an-ip, 10.0.0.3", ""))
+	assert.Equal(t, "198.51.100.1", request("10.0.0.2:1234", "", "198.51.100.1"))
+	assert.Equal(t, "10.0.0.2", request("10.0.0.2:1234", "", ""))
+}
@@ -61,7 +61,18 @@ func Write(ctx context.Context, writer http.ResponseWriter, data interface{}) {
 }
 
 func WriteError(ctx context.Context, writer http.ResponseWriter, errorResponse error) {
-	writer.WriteHeader(http.StatusBadRequest)
+	WriteErrorStatus(ctx, writer, http.StatusBadRequest, errorResponse)
+}
+
+// WriteErrorStatus writes the error as api.ApiResponse, or as problem details when the context asks for it.
+func WriteErrorStatus(ctx context.Context, writer http.ResponseWriter, httpStatus int, errorResponse error) {
+	if IsProblemDetails(ctx) {
+		writeProblem(writer, httpStatus, errorResponse)
+		return
+	}
+
+	writer.Header().Set(state.HttpHeaders().ContentType.String(), state.HttpContentTypeValues().ApplicationJson)
+	writer.WriteHeader(httpStatus)
 	response := api.ApiResponse{
 		Header: getHeader(writer),
 		Error:  errorResponse,
@@ -0,0 +1,51 @@
+package httphelper
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/mochammadshenna/arch-pba-template/internal/model/api"
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/helper"
+)
+
+type problemDetailsKey struct{}
+
+// WithProblemDetails marks the context so errors are rendered as application/problem+json.
+func WithProblemDetails(ctx context.Context) context.Context {
+	return context.WithValue(ctx, problemDetailsKey{}, true)
+}
+
+func IsProblemDetails(ctx context.Context) bool {
+	v, _ := ctx.Value(problemDetailsKey{}).(bool)
+	return v
+}
+
+// AcceptsProblemDetails reports whether the client listed application/problem+json in its Accept header.
+func AcceptsProblemDetails(request *http.Request) bool {
+	for _, accept := range request.Header.Values(state.HttpHeaders().Accept.String()) {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType := strings.TrimSpace(strings.SplitN(mediaRange, ";", 2)[0])
+			if strings.EqualFold(mediaType, state.HttpContentTypeValues().ApplicationProblemJson) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func writeProblem(writer http.ResponseWriter, httpStatus int, err error) {
+	errorResponse, ok := err.(api.ErrorResponse)
+	if !ok {
+		errorResponse = api.ErrorResponse{Message: err.Error()}
+	}
+
+	problem := api.NewProblemDetails(httpStatus, errorResponse, writer.Header().Get(state.HttpHeaders().RequestId.String()))
+
+	writer.Header().Set(state.HttpHeaders().ContentType.String(), state.HttpContentTypeValues().ApplicationProblemJson)
+	writer.WriteHeader(httpStatus)
+	encoder := json.NewEncoder(writer)
+	helper.PanicError(encoder.Encode(problem))
+}
@@ -0,0 +1,48 @@
+package httphelper
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mochammadshenna/arch-pba-template/internal/model/api"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/exceptioncode"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteErrorStatusProblemDetails(t *testing.T) {
+	errs := []api.ErrorValidate{{Key: "name", Code: "VALIDATION", Message: "required"}}
+	recorder := httptest.NewRecorder()
+	recorder.Header().Set("Request-Id", "req-1")
+
+	WriteErrorStatus(WithProblemDetails(context.Background()), recorder, http.StatusBadRequest, api.ErrorResponse{
+		Code:    exceptioncode.CodeInvalidValidation,
+		Message: "validation error",
+		Errors:  errs,
+	})
+
+	var problem struct {
+		api.ProblemDetails
+		Errors []api.ErrorValidate `json:"errors"`
+	}
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "urn:arch-pba:problem:invalid-validation", problem.Type)
+	assert.Equal(t, "Bad Request", problem.Title)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "validation error", problem.Detail)
+	assert.Equal(t, "req-1", problem.Instance)
+	assert.Equal(t, exceptioncode.CodeInvalidValidation, problem.Code)
+	assert.Equal(t, errs, problem.Errors)
+}
+
+func TestAcceptsProblemDetails(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.False(t, AcceptsProblemDetails(request))
+
+	request.Header.Set("Accept", "application/json;q=0.9, application/problem+json")
+	assert.True(t, AcceptsProblemDetails(request))
+}
@@ -0,0 +1,38 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// Record is what is kept for an Idempotency-Key, from the first request until its TTL ends.
+type Record struct {
+	Fingerprint string      `json:"fingerprint"`
+	Completed   bool        `json:"completed"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header"`
+	Body        []byte      `json:"body"`
+}
+
+type Store interface {
+	// Reserve saves record under key unless the key is taken, in which case the stored record is returned with false.
+	Reserve(ctx context.Context, key string, record Record, ttl time.Duration) (Record, bool, error)
+	// Complete replaces the reserved record with the finished response.
+	Complete(ctx context.Context, key string, record Record, ttl time.Duration) error
+	// Release removes a reservation so the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// Fingerprint identifies a request by method, path and body so a key cannot be reused for another payload.
+func Fingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
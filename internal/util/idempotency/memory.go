@@ -0,0 +1,94 @@
+package idempotency
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	record    Record
+	expiredAt time.Time
+}
+
+// expiry is queued for every write of a key, it is stale once the key was written again or released.
+type expiry struct {
+	key       string
+	expiredAt time.Time
+}
+
+// expiryQueue is a min-heap of expiries, the next key to expire is on top.
+type expiryQueue []expiry
+
+func (q expiryQueue) Len() int            { return len(q) }
+func (q expiryQueue) Less(i, j int) bool  { return q[i].expiredAt.Before(q[j].expiredAt) }
+func (q expiryQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *expiryQueue) Push(x interface{}) { *q = append(*q, x.(expiry)) }
+func (q *expiryQueue) Pop() interface{} {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
+
+// MemoryStore keeps records in the process memory, it is only suitable for a single instance.
+// Expired records are popped from a heap, a request only pays for the records that expired.
+type MemoryStore struct {
+	mu       sync.Mutex
+	entries  map[string]memoryEntry
+	expiries expiryQueue
+	now      func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: map[string]memoryEntry{},
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Reserve(ctx context.Context, key string, record Record, ttl time.Duration) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.evictExpired(now)
+
+	if entry, ok := s.entries[key]; ok {
+		return entry.record, false, nil
+	}
+
+	s.set(key, record, now.Add(ttl))
+	return record, true, nil
+}
+
+func (s *MemoryStore) Complete(ctx context.Context, key string, record Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(key, record, s.now().Add(ttl))
+	return nil
+}
+
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryStore) set(key string, record Record, expiredAt time.Time) {
+	s.entries[key] = memoryEntry{record: record, expiredAt: expiredAt}
+	heap.Push(&s.expiries, expiry{key: key, expiredAt: expiredAt})
+}
+
+func (s *MemoryStore) evictExpired(now time.Time) {
+	for len(s.expiries) > 0 && now.After(s.expiries[0].expiredAt) {
+		e := heap.Pop(&s.expiries).(expiry)
+		if entry, ok := s.entries[e.key]; ok && entry.expiredAt.Equal(e.expiredAt) {
+			delete(s.entries, e.key)
+		}
+	}
+}
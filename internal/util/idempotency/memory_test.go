@@ -0,0 +1,33 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreEvictsExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	_, reserved, _ := store.Reserve(ctx, "a", Record{Fingerprint: "a"}, time.Minute)
+	assert.True(t, reserved)
+	_, _, _ = store.Reserve(ctx, "b", Record{Fingerprint: "b"}, time.Hour)
+	// completing a key later extends it, its first expiry is stale
+	now = now.Add(30 * time.Second)
+	assert.NoError(t, store.Complete(ctx, "a", Record{Fingerprint: "a", Completed: true}, time.Minute))
+
+	now = now.Add(45 * time.Second)
+	existing, reserved, _ := store.Reserve(ctx, "a", Record{Fingerprint: "other"}, time.Minute)
+	assert.False(t, reserved)
+	assert.True(t, existing.Completed)
+
+	now = now.Add(time.Minute)
+	_, reserved, _ = store.Reserve(ctx, "a", Record{Fingerprint: "a"}, time.Minute)
+	assert.True(t, reserved)
+	assert.Contains(t, store.entries, "b")
+}
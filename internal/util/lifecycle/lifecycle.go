@@ -0,0 +1,170 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/mochammadshenna/arch-pba-template/internal/util/logger"
+)
+
+const DefaultGracePeriod = 15 * time.Second
+
+type StopFunc func(ctx context.Context) error
+
+type component struct {
+	name string
+	stop StopFunc
+}
+
+// Manager runs the http server until SIGINT or SIGTERM, then drains it and stops the components
+// in reverse order of Append, so a component is stopped before the ones it was built from.
+type Manager struct {
+	mu           sync.Mutex
+	components   []component
+	drainDelay   time.Duration
+	shuttingDown atomic.Bool
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+}
+
+func New() *Manager {
+	return &Manager{shutdown: make(chan struct{})}
+}
+
+// Append registers a started component, e.g. a subscriber, a background worker or a connection pool.
+func (m *Manager) Append(name string, stop StopFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.components = append(m.components, component{name: name, stop: stop})
+}
+
+// AppendCloser registers a component stopped with Close, like *sql.DB or *redis.Client.
+func (m *Manager) AppendCloser(name string, closer io.Closer) {
+	m.Append(name, func(context.Context) error {
+		return closer.Close()
+	})
+}
+
+// SetDrainDelay keeps the servers open for d after the shutdown starts while readiness fails, so the
+// load balancer stops routing new requests to the instance before its listeners close.
+func (m *Manager) SetDrainDelay(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.drainDelay = d
+}
+
+// ShuttingDown reports whether the shutdown started, readiness checks fail from then on.
+func (m *Manager) ShuttingDown() bool {
+	return m.shuttingDown.Load()
+}
+
+// Shutdown starts the graceful shutdown as if SIGTERM was received.
+func (m *Manager) Shutdown() {
+	m.shutdownOnce.Do(func() {
+		close(m.shutdown)
+	})
+}
+
+// Run serves the servers until a signal, Shutdown or a failing server, fails readiness for the drain
+// delay, then stops accepting connections and waits for in-flight requests. The components are stopped even if draining did
+// not finish within gracePeriod, both share the same deadline. http.ErrServerClosed is not an error.
+func (m *Manager) Run(gracePeriod time.Duration, servers ...*http.Server) error {
+	ctx := context.Background()
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultGracePeriod
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	serveErr := make(chan error, len(servers))
+	for _, server := range servers {
+		go func(server *http.Server) {
+			serveErr <- serve(server)
+		}(server)
+	}
+
+	var errs []error
+	drain := true
+	select {
+	case err := <-serveErr:
+		drain = false
+		if !errors.Is(err, http.ErrServerClosed) {
+			errs = append(errs, fmt.Errorf("serve: %w", err))
+		}
+	case sig := <-signals:
+		logger.Infof(ctx, "received %s, shutting down within %s", sig, gracePeriod)
+	case <-m.shutdown:
+		logger.Infof(ctx, "shutting down within %s", gracePeriod)
+	}
+	m.shuttingDown.Store(true)
+
+	m.mu.Lock()
+	drainDelay := m.drainDelay
+	m.mu.Unlock()
+	if drain && drainDelay > 0 {
+		// readiness fails from now on, keep serving until the probes took the instance out of rotation
+		logger.Infof(ctx, "readiness is failing, closing the listeners in %s", drainDelay)
+		select {
+		case <-time.After(drainDelay):
+		case sig := <-signals:
+			logger.Infof(ctx, "received %s, closing the listeners now", sig)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, gracePeriod)
+	defer cancel()
+
+	drainErrs := make([]error, len(servers))
+	var wg sync.WaitGroup
+	for i, server := range servers {
+		wg.Add(1)
+		go func(i int, server *http.Server) {
+			defer wg.Done()
+			if err := server.Shutdown(ctx); err != nil {
+				drainErrs[i] = fmt.Errorf("drain http server %s: %w", server.Addr, err)
+			}
+		}(i, server)
+	}
+	wg.Wait()
+
+	return errors.Join(append(append(errs, drainErrs...), m.Stop(ctx))...)
+}
+
+func serve(server *http.Server) error {
+	if server.TLSConfig != nil {
+		// the certificate comes from TLSConfig.GetCertificate
+		return fmt.Errorf("%s: %w", server.Addr, server.ListenAndServeTLS("", ""))
+	}
+	return fmt.Errorf("%s: %w", server.Addr, server.ListenAndServe())
+}
+
+// Stop stops the components in reverse order of Append, Run calls it after draining the server.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	components := make([]component, len(m.components))
+	copy(components, m.components)
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(components) - 1; i >= 0; i-- {
+		c := components[i]
+		if err := c.stop(ctx); err != nil {
+			logger.Errorf(ctx, "failed to stop %s; err=%+v", c.name, err)
+			errs = append(errs, fmt.Errorf("stop %s: %w", c.name, err))
+			continue
+		}
+		logger.Infof(ctx, "stopped %s", c.name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,69 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunStopsComponentsInReverseOrder(t *testing.T) {
+	manager := New()
+	stopped := []string{}
+	for _, name := range []string{"db", "redis", "alert", "worker"} {
+		name := name
+		manager.Append(name, func(context.Context) error {
+			stopped = append(stopped, name)
+			if name == "redis" {
+				return errors.New("already closed")
+			}
+			return nil
+		})
+	}
+
+	server := &http.Server{Addr: "127.0.0.1:0"}
+	done := make(chan error, 1)
+	go func() {
+		done <- manager.Run(time.Second, server, &http.Server{Addr: "127.0.0.1:0"})
+	}()
+
+	assert.False(t, manager.ShuttingDown())
+	manager.Shutdown()
+
+	select {
+	case err := <-done:
+		assert.ErrorContains(t, err, "stop redis: already closed")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after Shutdown")
+	}
+	assert.True(t, manager.ShuttingDown())
+	assert.Equal(t, []string{"worker", "alert", "redis", "db"}, stopped)
+}
+
+func TestRunReturnsServeError(t *testing.T) {
+	err := New().Run(time.Second, &http.Server{Addr: "127.0.0.1:-1"})
+	assert.ErrorContains(t, err, "serve: 127.0.0.1:-1:")
+}
+
+func TestRunFailsReadinessBeforeClosingListeners(t *testing.T) {
+	manager := New()
+	manager.SetDrainDelay(200 * time.Millisecond)
+	server := &http.Server{Addr: "127.0.0.1:0"}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- manager.Run(time.Second, server)
+	}()
+	manager.Shutdown()
+
+	assert.Eventually(t, manager.ShuttingDown, time.Second, time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("listeners closed before the drain delay")
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.NoError(t, <-done)
+}
@@ -3,6 +3,7 @@ package logger
 import (
 	"context"
 	"log"
+	"sync"
 
 	config "github.com/mochammadshenna/arch-pba-template/config"
 	"github.com/mochammadshenna/arch-pba-template/internal/state"
@@ -13,6 +14,8 @@ var Logger = logrus.New()
 
 var lf loggerField
 
+var subscribeOnce sync.Once
+
 func Init() {
 	lf = newLoggerField()
 
@@ -34,6 +37,25 @@ func Init() {
 		panic(err)
 	}
 	Logger.SetLevel(logLevel)
+
+	subscribeOnce.Do(func() {
+		config.Subscribe(updateLevel)
+	})
+}
+
+// updateLevel applies log.level when it changes in a reloaded config, a level set through
+// the admin server is kept across reloads that do not touch log.level.
+func updateLevel(old, new config.Config) {
+	if old.Log.Level == new.Log.Level || new.Log.Level == "" {
+		return
+	}
+
+	logLevel, err := logrus.ParseLevel(new.Log.Level)
+	if err != nil {
+		return
+	}
+	Logger.SetLevel(logLevel)
+	Logger.Infof("log level changed to %s", logLevel)
 }
 
 type loggerField struct {
@@ -42,6 +64,12 @@ type loggerField struct {
 	RequestMethod string `json:"requestMethod"`
 	Resource      string `json:"resource"`
 	Status        string `json:"status"`
+	Path          string `json:"path"`
+	ResponseSize  string `json:"responseSize"`
+	LatencyMs     string `json:"latencyMs"`
+	RemoteIp      string `json:"remoteIp"`
+	UserAgent     string `json:"userAgent"`
+	Stack         string `json:"stack"`
 
 	// Field handle by logger
 	Message        string `json:"message"`
@@ -56,6 +84,12 @@ func newLoggerField() loggerField {
 		RequestMethod:  "requestMethod",
 		Resource:       "resource",
 		Status:         "status",
+		Path:           "path",
+		ResponseSize:   "responseSize",
+		LatencyMs:      "latencyMs",
+		RemoteIp:       "remoteIp",
+		UserAgent:      "userAgent",
+		Stack:          "stack",
 		Message:        "message",
 		Severity:       "severity",
 		Timestamp:      "timestamp",
@@ -67,6 +101,15 @@ func LoggerField() loggerField {
 	return lf
 }
 
+// WithFields returns an entry carrying the context fields and the given fields.
+func WithFields(ctx context.Context, fields logrus.Fields) *logrus.Entry {
+	contextFields := withFields(ctx)
+	for k, v := range fields {
+		contextFields[k] = v
+	}
+	return Logger.WithFields(contextFields)
+}
+
 func Trace(ctx context.Context, args ...interface{}) {
 	fields := withFields(ctx)
 	if len(fields) > 0 {
@@ -196,7 +239,7 @@ func Panicf(ctx context.Context, format string, args ...interface{}) {
 func withFields(ctx context.Context) logrus.Fields {
 	fields := logrus.Fields{}
 
-	requestId := ctx.Value(state.HttpHeaders().RequestId)
+	requestId := state.RequestId(ctx)
 	if requestId != "" {
 		fields[LoggerField().RequestId] = requestId
 	}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/mochammadshenna/arch-pba-template/internal/state"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -20,3 +21,13 @@ func TestLogger(t *testing.T) {
 	assert.Equal(t, message, field.Message)
 	assert.EqualValues(t, severityInfo, field.Severity)
 }
+
+func TestLoggerRequestId(t *testing.T) {
+	var buf bytes.Buffer
+	Init()
+	Logger.SetOutput(&buf)
+	field := loggerField{}
+	Info(state.WithRequestId(context.Background(), "req-1"), "this is message")
+	json.Unmarshal(buf.Bytes(), &field)
+	assert.Equal(t, "req-1", field.RequestId)
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const evictInterval = time.Minute
+
+type bucket struct {
+	tokens    float64
+	window    time.Duration
+	updatedAt time.Time
+}
+
+// MemoryStore keeps the buckets in the process memory, it is only suitable for a single instance.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	evictedAt time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.evictFull(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rule.Limit), updatedAt: now}
+		s.buckets[key] = b
+	}
+
+	refill := float64(now.Sub(b.updatedAt)) / float64(rule.Window) * float64(rule.Limit)
+	b.tokens = math.Min(float64(rule.Limit), b.tokens+refill)
+	b.window = rule.Window
+	b.updatedAt = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	return NewResult(rule, allowed, b.tokens), nil
+}
+
+// evictFull drops the buckets that are refilled by now, they are the same as a new bucket.
+func (s *MemoryStore) evictFull(now time.Time) {
+	if now.Sub(s.evictedAt) < evictInterval {
+		return
+	}
+	s.evictedAt = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.updatedAt) >= b.window {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	rule := Rule{Limit: 2, Window: time.Minute}
+
+	first, _ := store.Allow(context.Background(), "ip:1", rule)
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 1, first.Remaining)
+
+	second, _ := store.Allow(context.Background(), "ip:1", rule)
+	assert.True(t, second.Allowed)
+	assert.Equal(t, 0, second.Remaining)
+
+	denied, _ := store.Allow(context.Background(), "ip:1", rule)
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, 30*time.Second, denied.RetryAfter)
+
+	other, _ := store.Allow(context.Background(), "ip:2", rule)
+	assert.True(t, other.Allowed)
+
+	now = now.Add(30 * time.Second)
+	refilled, _ := store.Allow(context.Background(), "ip:1", rule)
+	assert.True(t, refilled.Allowed)
+}
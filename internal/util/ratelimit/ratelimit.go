@@ -0,0 +1,42 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Rule allows Limit requests per Window as a token bucket, bursts up to Limit are allowed.
+type Rule struct {
+	Limit  int
+	Window time.Duration
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next request is allowed, zero when allowed
+}
+
+type Store interface {
+	// Allow takes a token from the bucket of key.
+	Allow(ctx context.Context, key string, rule Rule) (Result, error)
+}
+
+// NewResult builds the result of a bucket holding tokens after the request was counted.
+func NewResult(rule Rule, allowed bool, tokens float64) Result {
+	perToken := rule.Window / time.Duration(rule.Limit)
+
+	result := Result{
+		Allowed:    allowed,
+		Limit:      rule.Limit,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: time.Duration((float64(rule.Limit) - tokens) * float64(perToken)),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) * float64(perToken))
+	}
+	return result
+}
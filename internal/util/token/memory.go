@@ -0,0 +1,75 @@
+package token
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps tokens in the process memory, it is only suitable for a single instance.
+type MemoryStore struct {
+	mu            sync.Mutex
+	refreshTokens map[string]RefreshToken
+	revoked       map[string]time.Time
+	now           func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		refreshTokens: map[string]RefreshToken{},
+		revoked:       map[string]time.Time{},
+		now:           time.Now,
+	}
+}
+
+func (s *MemoryStore) SaveRefreshToken(ctx context.Context, hash string, refreshToken RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictExpired()
+	s.refreshTokens[hash] = refreshToken
+	return nil
+}
+
+func (s *MemoryStore) ConsumeRefreshToken(ctx context.Context, hash string) (RefreshToken, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	refreshToken, ok := s.refreshTokens[hash]
+	delete(s.refreshTokens, hash)
+	if !ok || s.now().After(refreshToken.ExpiresAt) {
+		return RefreshToken{}, false, nil
+	}
+	return refreshToken, true, nil
+}
+
+func (s *MemoryStore) Revoke(ctx context.Context, tokenId string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictExpired()
+	s.revoked[tokenId] = expiresAt
+	return nil
+}
+
+func (s *MemoryStore) IsRevoked(ctx context.Context, tokenId string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.revoked[tokenId]
+	return ok, nil
+}
+
+func (s *MemoryStore) evictExpired() {
+	now := s.now()
+	for hash, refreshToken := range s.refreshTokens {
+		if now.After(refreshToken.ExpiresAt) {
+			delete(s.refreshTokens, hash)
+		}
+	}
+	for tokenId, expiresAt := range s.revoked {
+		if now.After(expiresAt) {
+			delete(s.revoked, tokenId)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package token
+
+import (
+	"context"
+	"time"
+)
+
+type RefreshToken struct {
+	UserId    int64     `json:"userId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Store keeps refresh tokens by hash and the IDs of revoked access tokens until they expire.
+type Store interface {
+	SaveRefreshToken(ctx context.Context, hash string, refreshToken RefreshToken) error
+	// ConsumeRefreshToken removes and returns the refresh token, so it can only be used once.
+	ConsumeRefreshToken(ctx context.Context, hash string) (RefreshToken, bool, error)
+	Revoke(ctx context.Context, tokenId string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, tokenId string) (bool, error)
+}
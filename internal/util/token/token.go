@@ -0,0 +1,149 @@
+package token
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/mochammadshenna/arch-pba-template/internal/util/random"
+)
+
+const refreshTokenLength = 64
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims carries the roles and permissions of the user when the token was issued,
+// role changes apply from the next refresh.
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// Manager issues and verifies access tokens, and generates refresh tokens.
+type Manager struct {
+	method          jwt.SigningMethod
+	signKey         interface{}
+	verifyKey       interface{}
+	issuer          string
+	accessTokenTtl  time.Duration
+	refreshTokenTtl time.Duration
+	now             func() time.Time
+}
+
+func NewManager(authConfig config.AuthConfig) (*Manager, error) {
+	manager := &Manager{
+		issuer:          authConfig.Issuer,
+		accessTokenTtl:  authConfig.AccessTokenTtl,
+		refreshTokenTtl: authConfig.RefreshTokenTtl,
+		now:             time.Now,
+	}
+
+	switch authConfig.Algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		if authConfig.Secret == "" {
+			return nil, errors.New("auth.secret is required for HS256")
+		}
+		manager.method = jwt.SigningMethodHS256
+		manager.signKey = []byte(authConfig.Secret)
+		manager.verifyKey = []byte(authConfig.Secret)
+	case jwt.SigningMethodRS256.Alg():
+		privateKey, publicKey, err := loadRsaKeys(authConfig.PrivateKeyFile, authConfig.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		manager.method = jwt.SigningMethodRS256
+		manager.signKey = privateKey
+		manager.verifyKey = publicKey
+	default:
+		return nil, fmt.Errorf("unsupported auth.algorithm %q, expected HS256 or RS256", authConfig.Algorithm)
+	}
+
+	return manager, nil
+}
+
+func loadRsaKeys(privateKeyFile, publicKeyFile string) (*rsa.PrivateKey, *rsa.PublicKey, error) {
+	privatePem, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read auth.privateKeyFile: %w", err)
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePem)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse auth.privateKeyFile: %w", err)
+	}
+
+	if publicKeyFile == "" {
+		return privateKey, &privateKey.PublicKey, nil
+	}
+
+	publicPem, err := os.ReadFile(publicKeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read auth.publicKeyFile: %w", err)
+	}
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPem)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse auth.publicKeyFile: %w", err)
+	}
+	return privateKey, publicKey, nil
+}
+
+func (m *Manager) IssueAccessToken(subject string, roles, permissions []string) (string, Claims, error) {
+	now := m.now()
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Issuer:    m.issuer,
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.accessTokenTtl)),
+		},
+		Roles:       roles,
+		Permissions: permissions,
+	}
+
+	signed, err := jwt.NewWithClaims(m.method, claims).SignedString(m.signKey)
+	return signed, claims, err
+}
+
+// ParseAccessToken verifies the signature, algorithm, issuer and expiry of an access token.
+func (m *Manager) ParseAccessToken(accessToken string) (Claims, error) {
+	claims := Claims{}
+	_, err := jwt.ParseWithClaims(accessToken, &claims, func(t *jwt.Token) (interface{}, error) {
+		return m.verifyKey, nil
+	},
+		jwt.WithValidMethods([]string{m.method.Alg()}),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(m.now),
+	)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+// NewRefreshToken returns an opaque refresh token, only its hash should be stored.
+func (m *Manager) NewRefreshToken() (string, error) {
+	return random.GenerateRandomString(random.Alphanum, refreshTokenLength)
+}
+
+func (m *Manager) AccessTokenTtl() time.Duration {
+	return m.accessTokenTtl
+}
+
+func (m *Manager) RefreshTokenTtl() time.Duration {
+	return m.refreshTokenTtl
+}
+
+func HashRefreshToken(refreshToken string) string {
+	hash := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,54 @@
+package token
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	config "github.com/mochammadshenna/arch-pba-template/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	manager, err := NewManager(config.AuthConfig{
+		Algorithm:       "HS256",
+		Secret:          "secret",
+		Issuer:          "arch-pba",
+		AccessTokenTtl:  time.Minute,
+		RefreshTokenTtl: time.Hour,
+	})
+	assert.NoError(t, err)
+	return manager
+}
+
+func TestIssueAndParseAccessToken(t *testing.T) {
+	manager := newTestManager(t)
+
+	accessToken, issued, err := manager.IssueAccessToken("42", []string{"admin"}, []string{"brand:read"})
+	assert.NoError(t, err)
+
+	claims, err := manager.ParseAccessToken(accessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "42", claims.Subject)
+	assert.Equal(t, issued.ID, claims.ID)
+	assert.Equal(t, []string{"admin"}, claims.Roles)
+	assert.Equal(t, []string{"brand:read"}, claims.Permissions)
+}
+
+func TestParseAccessTokenRejectsInvalidTokens(t *testing.T) {
+	manager := newTestManager(t)
+	accessToken, _, _ := manager.IssueAccessToken("42", nil, nil)
+
+	other, _ := NewManager(config.AuthConfig{Algorithm: "HS256", Secret: "other", Issuer: "arch-pba", AccessTokenTtl: time.Minute})
+	_, err := other.ParseAccessToken(accessToken)
+	assert.True(t, errors.Is(err, ErrInvalidToken))
+
+	manager.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	_, err = manager.ParseAccessToken(accessToken)
+	assert.True(t, errors.Is(err, ErrInvalidToken))
+}
+
+func TestNewManagerRejectsUnknownAlgorithm(t *testing.T) {
+	_, err := NewManager(config.AuthConfig{Algorithm: "none"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version, e.g. 1.4.2 or v2.0.0-beta.1
+// Build metadata is accepted but ignored when comparing.
+type Version struct {
+	Major      int64
+	Minor      int64
+	Patch      int64
+	PreRelease string
+}
+
+func Parse(s string) (Version, error) {
+	raw := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	raw, _, _ = strings.Cut(raw, "+")
+	raw, preRelease, _ := strings.Cut(raw, "-")
+
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("invalid version %q, expected major.minor.patch", s)
+	}
+
+	numbers := make([]int64, len(parts))
+	for i, part := range parts {
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid version %q, expected major.minor.patch", s)
+		}
+		numbers[i] = n
+	}
+
+	return Version{
+		Major:      numbers[0],
+		Minor:      numbers[1],
+		Patch:      numbers[2],
+		PreRelease: preRelease,
+	}, nil
+}
+
+// Compare returns -1, 0 or 1 when v is lower, equal or greater than o.
+// A pre-release is lower than its release, pre-releases are compared as strings.
+func (v Version) Compare(o Version) int {
+	for _, diff := range []int64{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if diff < 0 {
+			return -1
+		}
+		if diff > 0 {
+			return 1
+		}
+	}
+
+	switch {
+	case v.PreRelease == o.PreRelease:
+		return 0
+	case v.PreRelease == "":
+		return 1
+	case o.PreRelease == "":
+		return -1
+	}
+	return strings.Compare(v.PreRelease, o.PreRelease)
+}
+
+func (v Version) LessThan(o Version) bool {
+	return v.Compare(o) < 0
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.PreRelease != "" {
+		s += "-" + v.PreRelease
+	}
+	return s
+}
+
+// ParseApiVersion parses an API version like v2 or 2 of the Api-Version header.
+func ParseApiVersion(s string) (int, bool) {
+	raw := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "v"), "V")
+	if raw == "" || strings.ContainsAny(raw, ".-+") {
+		return 0, false
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
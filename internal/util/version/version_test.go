@@ -0,0 +1,51 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3+build.7", 0},
+		{"1.2.3", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"2.0.0-beta.1", "2.0.0", -1},
+		{"2.0.0-beta.2", "2.0.0-beta.1", 1},
+	}
+
+	for _, c := range cases {
+		a, err := Parse(c.a)
+		assert.NoError(t, err)
+		b, err := Parse(c.b)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, a.Compare(b), "%s vs %s", c.a, c.b)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, s := range []string{"", "1.2", "1.2.x", "1.-2.3"} {
+		_, err := Parse(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParseApiVersion(t *testing.T) {
+	v, ok := ParseApiVersion("v2")
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+
+	v, ok = ParseApiVersion("3")
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+
+	_, ok = ParseApiVersion("1.4.0")
+	assert.False(t, ok)
+	_, ok = ParseApiVersion("v0")
+	assert.False(t, ok)
+}
@@ -0,0 +1,53 @@
+-- migrate:up
+CREATE TABLE roles(
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    description VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE permissions(
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE role_permissions(
+    role_id BIGINT NOT NULL,
+    permission_id BIGINT NOT NULL,
+    PRIMARY KEY (role_id, permission_id),
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    FOREIGN KEY (permission_id) REFERENCES permissions(id) ON DELETE CASCADE
+);
+
+CREATE TABLE user_roles(
+    user_id BIGINT NOT NULL,
+    role_id BIGINT NOT NULL,
+    PRIMARY KEY (user_id, role_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE
+);
+
+INSERT INTO roles(name, description) VALUES
+    ('admin', 'CMS admin, manages every brand and hotel'),
+    ('hotelier', 'Manages the hotels they own');
+
+INSERT INTO permissions(name, description) VALUES
+    ('brand:read', 'List and view brands'),
+    ('brand:manage', 'Create, update and delete every brand'),
+    ('hotel:read', 'List and view hotels'),
+    ('hotel:write', 'Create and update own hotels'),
+    ('hotel:manage', 'Create, update and delete every hotel');
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+WHERE r.name = 'admin';
+
+INSERT INTO role_permissions(role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+WHERE r.name = 'hotelier' AND p.name IN ('brand:read', 'hotel:read', 'hotel:write');
+
+-- migrate:down
+DROP TABLE user_roles;
+DROP TABLE role_permissions;
+DROP TABLE permissions;
+DROP TABLE roles;